@@ -0,0 +1,496 @@
+package main
+
+import (
+	"fmt"
+	"go/types"
+	"strconv"
+	"strings"
+)
+
+// decodeValue 生成解码val的代码，优先级与query包一致：Decoder、时间、标准库类型、TextUnmarshaler、
+// sql.Scanner、类型
+func (g *generator) decodeValue(val value, k key) error {
+	t := val.typ
+	switch u := t.Underlying().(type) {
+	case *types.Pointer:
+		elem := u.Elem()
+		// 标准库类型的指针直接指向解析结果
+		if stdType(elem) != "" {
+			s := g.name("s")
+			g.p("if %s, ok := qsgenValue(values, %s); ok {", s, k.expr)
+			g.decodeStd(value{expr: val.expr, typ: elem, path: val.path, ff: val.ff}, s, k.expr, true)
+			g.p("}")
+			return nil
+		}
+		if g.containsDecoder(elem, map[types.Type]bool{}) {
+			// 没有对应参数时，nil指针仅在解码出内容时才会被赋值
+			p := g.name("p")
+			g.p("{")
+			g.p("%s := %s", p, val.expr)
+			g.p("if %s == nil {", p)
+			g.p("%s = new(%s)", p, g.typeExpr(elem))
+			g.p("}")
+			if err := g.decodeValue(value{expr: "(*" + p + ")", typ: elem, addr: true, path: val.path, ff: val.ff}, k); err != nil {
+				return err
+			}
+			g.p("if %s == nil && (qsgenHas(values, %s) || !reflect.ValueOf(%s).Elem().IsZero()) {", val.expr, k.expr, p)
+			g.p("%s = %s", val.expr, p)
+			g.p("}")
+			g.p("}")
+			return nil
+		}
+		g.p("if qsgenHas(values, %s) {", k.expr)
+		g.p("if %s == nil {", val.expr)
+		g.p("%s = new(%s)", val.expr, g.typeExpr(elem))
+		g.p("}")
+		if err := g.decodeValue(value{expr: "(*" + val.expr + ")", typ: elem, addr: true, path: val.path, ff: val.ff}, k); err != nil {
+			return err
+		}
+		g.p("}")
+		return nil
+	case *types.Interface:
+		return g.unsupported(val, "decoding")
+	}
+
+	// 自定义Decode方法
+	if g.isDecoder(t) {
+		g.p("if err := %s.DecodeValues(%s, values); err != nil {", val.expr, k.expr)
+		g.p("return err")
+		g.p("}")
+		return nil
+	}
+
+	isBytes, _, err := g.bytesOf(val)
+	if err != nil {
+		return err
+	}
+	if isBytes || g.isLeaf(t) {
+		s := g.name("s")
+		g.p("if %s, ok := qsgenValue(values, %s); ok {", s, k.expr)
+		if isBytes {
+			err = g.decodeBytes(val, s, k.expr)
+		} else {
+			err = g.decodeLeaf(val, s, k.expr)
+		}
+		if err != nil {
+			return err
+		}
+		g.p("}")
+		return nil
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Struct:
+		return g.decodeStruct(val, k)
+	case *types.Slice, *types.Array:
+		return g.decodeSlice(val, k)
+	case *types.Map:
+		return g.decodeMap(val, k, u)
+	}
+	return g.unsupported(val, "decoding")
+}
+
+// containsDecoder 类型本身或其嵌套的结构体字段中存在自定义的Decoder，规则与query包一致，
+// 生成的DecodeValues只读取以key为前缀的参数，没有对应参数时不需要解码
+func (g *generator) containsDecoder(t types.Type, visited map[types.Type]bool) bool {
+	if g.isTarget(t) {
+		return false
+	}
+	if hasMethod(t, "DecodeValues") || hasMethod(types.NewPointer(t), "DecodeValues") {
+		return true
+	}
+	for {
+		p, ok := t.Underlying().(*types.Pointer)
+		if !ok {
+			break
+		}
+		t = p.Elem()
+	}
+	st, ok := t.Underlying().(*types.Struct)
+	if !ok || visited[t] {
+		return false
+	}
+	visited[t] = true
+	for i := 0; i < st.NumFields(); i++ {
+		sf := st.Field(i)
+		if !sf.Exported() && !sf.Embedded() {
+			continue
+		}
+		if g.containsDecoder(sf.Type(), visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// isLeaf 是否为从单个值解码的类型
+func (g *generator) isLeaf(t types.Type) bool {
+	if g.isDecoder(t) {
+		return false
+	}
+	if isTime(t) || stdType(t) != "" || hasMethod(types.NewPointer(t), "UnmarshalText") || hasMethod(types.NewPointer(t), "Scan") {
+		return true
+	}
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Info()&(types.IsString|types.IsBoolean|types.IsInteger|types.IsFloat) != 0
+}
+
+// decodeLeaf 生成从字符串表达式s解码val的代码，k为错误信息中的参数名
+func (g *generator) decodeLeaf(val value, s, k string) error {
+	e := val.expr
+	t := val.typ
+	if isTime(t) {
+		layout := val.ff.timeLayout
+		if layout == "" {
+			layout = defaultTimeLayout
+		}
+		tm := g.name("t")
+		g.p("%s, err := qsgenParseTime(%s, %s)", tm, s, strconv.Quote(layout))
+		g.p("if err != nil {")
+		g.p(`return fmt.Errorf("invalid time value of %%s: %%v", %s, err)`, k)
+		g.p("}")
+		g.p("%s = %s", e, tm)
+		return nil
+	}
+	if stdType(t) != "" {
+		g.decodeStd(val, s, k, false)
+		return nil
+	}
+	if hasMethod(types.NewPointer(t), "UnmarshalText") {
+		g.p("if err := %s.UnmarshalText([]byte(%s)); err != nil {", e, s)
+		g.p(`return fmt.Errorf("invalid value of %%s: %%v", %s, err)`, k)
+		g.p("}")
+		return nil
+	}
+	// sql.NullString等类型解码值字段并设置Valid，其他类型将字符串传给Scan
+	if hasMethod(types.NewPointer(t), "Scan") {
+		if f, ok := nullField(t); ok {
+			if err := g.decodeLeaf(value{expr: e + "." + f.Name(), typ: f.Type(), addr: true, path: val.path, ff: val.ff}, s, k); err != nil {
+				return err
+			}
+			g.p("%s.Valid = true", e)
+			return nil
+		}
+		g.p("if err := %s.Scan(%s); err != nil {", e, s)
+		g.p(`return fmt.Errorf("invalid value of %%s: %%v", %s, err)`, k)
+		g.p("}")
+		return nil
+	}
+	b, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		return g.unsupported(val, "decoding")
+	}
+	info := b.Info()
+	if info&types.IsString != 0 {
+		g.p("%s = %s", e, g.conv(t, s, types.Typ[types.String]))
+		return nil
+	}
+	var parse, kind string
+	var from types.Type
+	switch {
+	case info&types.IsBoolean != 0 && val.ff.boolSet:
+		parse, kind, from = fmt.Sprintf("qsgenParseBool(%s, %s, %s)", s, strconv.Quote(val.ff.boolT), strconv.Quote(val.ff.boolF)), "bool", types.Typ[types.Bool]
+	case info&types.IsBoolean != 0:
+		parse, kind, from = fmt.Sprintf("strconv.ParseBool(%s)", s), "bool", types.Typ[types.Bool]
+	case info&types.IsUnsigned != 0:
+		parse, kind, from = fmt.Sprintf("strconv.ParseUint(%s, 10, %s)", s, bitSize(b)), "uint", types.Typ[types.Uint64]
+	case info&types.IsInteger != 0:
+		parse, kind, from = fmt.Sprintf("strconv.ParseInt(%s, 10, %s)", s, bitSize(b)), "int", types.Typ[types.Int64]
+	case info&types.IsFloat != 0:
+		parse, kind, from = fmt.Sprintf("strconv.ParseFloat(%s, %s)", s, bitSize(b)), "float", types.Typ[types.Float64]
+	default:
+		return g.unsupported(val, "decoding")
+	}
+	n := g.name("n")
+	g.p("%s, err := %s", n, parse)
+	g.p("if err != nil {")
+	g.p(`return fmt.Errorf("invalid %s value of %%s: %%q", %s, %s)`, kind, k, s)
+	g.p("}")
+	g.p("%s = %s", e, g.conv(t, n, from))
+	return nil
+}
+
+// decodeStd 生成从字符串表达式s解析标准库类型的代码，ptr为true时val.expr为指向该类型的指针
+func (g *generator) decodeStd(val value, s, k string, ptr bool) {
+	name := stdType(val.typ)
+	p := g.name("p")
+	switch name {
+	case "Duration":
+		g.p("%s, err := qsgenParseDuration(%s, %s)", p, s, strconv.Quote(val.ff.duration))
+	case "Location":
+		g.p("%s, err := time.LoadLocation(%s)", p, s)
+	case "URL":
+		g.p("%s, err := url.Parse(%s)", p, s)
+	case "IPNet":
+		g.p("%s, err := qsgenParseIPNet(%s)", p, s)
+	case "Regexp":
+		g.p("%s, err := regexp.Compile(%s)", p, s)
+	default:
+		g.p("%s := new(%s)", p, g.typeExpr(val.typ))
+		g.p("err := %s.UnmarshalText([]byte(%s))", p, s)
+	}
+	g.p("if err != nil {")
+	g.p(`return fmt.Errorf("invalid value of %%s: %%v", %s, err)`, k)
+	g.p("}")
+	switch {
+	case name == "Duration" && ptr:
+		g.p("%s = &%s", val.expr, p)
+	case name == "Duration" || ptr:
+		g.p("%s = %s", val.expr, p)
+	default:
+		g.p("%s = *%s", val.expr, p)
+	}
+}
+
+// bitSize 数值类型的位数
+func bitSize(b *types.Basic) string {
+	switch b.Kind() {
+	case types.Int8, types.Uint8:
+		return "8"
+	case types.Int16, types.Uint16:
+		return "16"
+	case types.Int32, types.Uint32, types.Float32:
+		return "32"
+	case types.Int64, types.Uint64, types.Float64:
+		return "64"
+	}
+	return "strconv.IntSize"
+}
+
+// decodeElem 生成解码数组、map元素的代码，vs为元素的值
+func (g *generator) decodeElem(val value, vs, k string) error {
+	if p, ok := val.typ.Underlying().(*types.Pointer); ok {
+		g.p("if %s == nil {", val.expr)
+		g.p("%s = new(%s)", val.expr, g.typeExpr(p.Elem()))
+		g.p("}")
+		return g.decodeElem(value{expr: "(*" + val.expr + ")", typ: p.Elem(), addr: true, path: val.path, ff: val.ff}, vs, k)
+	}
+	if g.isDecoder(val.typ) {
+		g.p("if err := %s.DecodeValues(%s, values); err != nil {", val.expr, k)
+		g.p("return err")
+		g.p("}")
+		return nil
+	}
+	isBytes, _, err := g.bytesOf(val)
+	if err != nil {
+		return err
+	}
+	if !isBytes && !g.isLeaf(val.typ) {
+		return g.unsupported(val, "decoding elements")
+	}
+	g.p("if len(%s) > 0 {", vs)
+	if isBytes {
+		err = g.decodeBytes(val, vs+"[0]", k)
+	} else {
+		err = g.decodeLeaf(val, vs+"[0]", k)
+	}
+	if err != nil {
+		return err
+	}
+	g.p("}")
+	return nil
+}
+
+// decodeBytes 生成按格式从字符串表达式s解码字节切片、字节数组的代码，数组的长度需与解码结果一致
+func (g *generator) decodeBytes(val value, s, k string) error {
+	_, n, err := g.bytesOf(val)
+	if err != nil {
+		return err
+	}
+	b := g.name("b")
+	g.p("%s, err := qsgenDecodeBytes(%s, %s)", b, s, strconv.Quote(val.ff.bytes))
+	g.p("if err != nil {")
+	g.p(`return fmt.Errorf("invalid bytes value of %%s: %%v", %s, err)`, k)
+	g.p("}")
+	if n < 0 {
+		g.p("%s = %s", val.expr, g.conv(val.typ, b, types.NewSlice(types.Typ[types.Byte])))
+		return nil
+	}
+	g.p("if len(%s) > 0 {", b)
+	g.p("if len(%s) != %d {", b, n)
+	g.p(`return fmt.Errorf("%%s: decoded %%d bytes for array of length %d", %s, len(%s))`, n, k, b)
+	g.p("}")
+	g.p("copy(%s[:], %s)", val.expr, b)
+	g.p("}")
+	return nil
+}
+
+// decodeStruct 解码结构体的字段
+func (g *generator) decodeStruct(val value, k key) error {
+	if err := g.enter(val.typ); err != nil {
+		return err
+	}
+	defer g.leave()
+	fs, err := g.fields(val.typ.Underlying().(*types.Struct))
+	if err != nil {
+		return err
+	}
+	for _, f := range fs {
+		fv := value{expr: val.expr + "." + f.goName, typ: f.typ, addr: true, path: val.path, ff: val.ff}
+		if !f.embedded {
+			fv.path = fieldPath(val.path, f.goName)
+			fv.ff = f.ff
+			err := g.bind(childKey(k, f.name), func(fk key) error {
+				return g.decodeValue(fv, fk)
+			})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		// 嵌套结构体使用上一层的参数名，nil指针仅在解码出内容时才会被赋值
+		p, ok := f.typ.Underlying().(*types.Pointer)
+		if !ok {
+			if err := g.decodeValue(fv, k); err != nil {
+				return err
+			}
+			continue
+		}
+		// 私有的嵌套结构体指针无法赋值
+		if !isExported(f.goName) {
+			continue
+		}
+		tmp := g.name("p")
+		g.p("{")
+		g.p("%s := %s", tmp, fv.expr)
+		g.p("if %s == nil {", tmp)
+		g.p("%s = new(%s)", tmp, g.typeExpr(p.Elem()))
+		g.p("}")
+		if err := g.decodeValue(value{expr: "(*" + tmp + ")", typ: p.Elem(), addr: true, path: val.path, ff: val.ff}, k); err != nil {
+			return err
+		}
+		g.p("if %s == nil && !reflect.ValueOf(%s).Elem().IsZero() {", fv.expr, tmp)
+		g.p("%s = %s", fv.expr, tmp)
+		g.p("}")
+		g.p("}")
+	}
+	return nil
+}
+
+// isExported 名称是否为导出的
+func isExported(name string) bool {
+	return name != "" && name[0] >= 'A' && name[0] <= 'Z'
+}
+
+// decodeSlice 解码数组、切片，元素的规则与query包一致
+func (g *generator) decodeSlice(val value, k key) error {
+	var elem types.Type
+	n := int64(-1)
+	switch u := val.typ.Underlying().(type) {
+	case *types.Slice:
+		elem = u.Elem()
+	case *types.Array:
+		elem = u.Elem()
+		n = u.Len()
+	}
+	if k.top {
+		return g.unsupported(val, "decoding")
+	}
+	delimiter := ""
+	if val.ff.arrayFormat == "delimited" {
+		delimiter = val.ff.delimiter
+	}
+	es, i, vs := g.name("e"), g.name("i"), g.name("vs")
+	ek := dynamicKey(k, "strconv.Itoa("+i+")").expr
+
+	g.p("{")
+	g.p("%s, err := qsgenElements(values, %s, %s, %d)", es, k.expr, strconv.Quote(delimiter), n)
+	g.p("if err != nil {")
+	g.p(`return fmt.Errorf("%%s: %%v", %s, err)`, k.expr)
+	g.p("}")
+	if n < 0 {
+		s := g.name("s")
+		g.p("if len(%s) > 0 {", es)
+		g.p("%s := make(%s, len(%s))", s, g.typeExpr(val.typ), es)
+		if err := g.elemLoop(value{expr: s + "[" + i + "]", typ: elem, addr: true, path: val.path, ff: val.ff}, i, vs, es, ek); err != nil {
+			return err
+		}
+		g.p("%s = %s", val.expr, s)
+		g.p("}")
+	} else {
+		if err := g.elemLoop(value{expr: val.expr + "[" + i + "]", typ: elem, addr: true, path: val.path, ff: val.ff}, i, vs, es, ek); err != nil {
+			return err
+		}
+	}
+	g.p("}")
+	return nil
+}
+
+// elemLoop 生成遍历元素es并解码到val的循环，i、vs为下标及元素值的变量名
+func (g *generator) elemLoop(val value, i, vs, es, k string) error {
+	body, err := g.capture(func() error {
+		return g.decodeElem(val, vs, k)
+	})
+	if err != nil {
+		return err
+	}
+	if strings.Contains(body, vs) {
+		g.p("for %s, %s := range %s {", i, vs, es)
+	} else {
+		g.p("for %s := range %s {", i, es)
+	}
+	g.buf.WriteString(body)
+	g.p("}")
+	return nil
+}
+
+// decodeMap 解码map，key的规则与query包一致
+func (g *generator) decodeMap(val value, k key, m *types.Map) error {
+	if k.top {
+		return g.unsupported(val, "decoding")
+	}
+	kt := m.Key()
+	b, isBasic := kt.Underlying().(*types.Basic)
+	var keyParse string
+	var from types.Type
+	switch {
+	case isBasic && b.Info()&types.IsString != 0:
+	case hasMethod(types.NewPointer(kt), "UnmarshalText"):
+	case isBasic && b.Info()&types.IsBoolean != 0:
+		keyParse, from = "strconv.ParseBool(%s)", types.Typ[types.Bool]
+	case isBasic && b.Info()&types.IsUnsigned != 0:
+		keyParse, from = "strconv.ParseUint(%s, 10, "+bitSize(b)+")", types.Typ[types.Uint64]
+	case isBasic && b.Info()&types.IsInteger != 0:
+		keyParse, from = "strconv.ParseInt(%s, 10, "+bitSize(b)+")", types.Typ[types.Int64]
+	default:
+		return g.unsupported(value{typ: kt, path: val.path}, "map keys")
+	}
+	cs, seg, vs, mv, mk := g.name("c"), g.name("seg"), g.name("vs"), g.name("mv"), g.name("mk")
+	ek := dynamicKey(k, seg).expr
+	g.p("if %s := qsgenChildren(values, %s); len(%s) > 0 {", cs, k.expr, cs)
+	g.p("if %s == nil {", val.expr)
+	g.p("%s = make(%s, len(%s))", val.expr, g.typeExpr(val.typ), cs)
+	g.p("}")
+	g.p("for _, %s := range %s {", seg, cs)
+	g.p("var %s %s", mv, g.typeExpr(m.Elem()))
+	body, err := g.capture(func() error {
+		return g.decodeElem(value{expr: mv, typ: m.Elem(), addr: true, path: val.path, ff: val.ff}, vs, ek)
+	})
+	if err != nil {
+		return err
+	}
+	if strings.Contains(body, vs) {
+		g.p("%s := values[%s]", vs, ek)
+	}
+	g.buf.WriteString(body)
+	keyExpr := mk
+	switch {
+	case isBasic && b.Info()&types.IsString != 0:
+		keyExpr = g.conv(kt, seg, types.Typ[types.String])
+	case keyParse == "":
+		g.p("var %s %s", mk, g.typeExpr(kt))
+		g.p("if err := %s.UnmarshalText([]byte(%s)); err != nil {", mk, seg)
+		g.p(`return fmt.Errorf("invalid map key of %%s: %%v", %s, err)`, k.expr)
+		g.p("}")
+	default:
+		g.p("%s, err := %s", mk, fmt.Sprintf(keyParse, seg))
+		g.p("if err != nil {")
+		g.p(`return fmt.Errorf("invalid map key of %%s: %%v", %s, err)`, k.expr)
+		g.p("}")
+		keyExpr = g.conv(kt, mk, from)
+	}
+	g.p("%s[%s] = %s", val.expr, keyExpr, mv)
+	g.p("}")
+	g.p("}")
+	return nil
+}
@@ -0,0 +1,569 @@
+package main
+
+import (
+	"fmt"
+	"go/types"
+	"strconv"
+	"strings"
+)
+
+// encodeValue 生成编码val的代码，优先级与query包一致：时间、标准库类型、Encoder、TextMarshaler、
+// driver.Valuer、Stringer、类型
+// out不为nil时只允许输出单个值，用于分隔符格式的数组元素
+func (g *generator) encodeValue(val value, k key, out func(string) string) error {
+	leaf := out != nil
+	if out == nil {
+		out = func(s string) string {
+			return fmt.Sprintf("v.Add(%s, %s)", k.expr, s)
+		}
+	}
+	t := val.typ
+	switch u := t.Underlying().(type) {
+	case *types.Pointer:
+		g.p("if %s != nil {", val.expr)
+		elem := value{expr: "(*" + val.expr + ")", typ: u.Elem(), addr: true, path: val.path, ff: val.ff, depth: val.depth}
+		var err error
+		if leaf {
+			err = g.encodeValue(elem, k, out)
+		} else {
+			err = g.encodeValue(elem, k, nil)
+		}
+		g.p("}")
+		return err
+	case *types.Interface:
+		return g.unsupported(val, "encoding")
+	}
+
+	// 时间格式
+	if isTime(t) {
+		g.p("%s", out(g.formatTime(val.expr, val.ff.timeLayout)))
+		return nil
+	}
+
+	// 标准库类型
+	if name := stdType(t); name != "" {
+		g.encodeStd(val, k, name, out)
+		return nil
+	}
+
+	// 自定义Encode方法，生成的方法沿用当前的层数
+	if g.isEncoder(t) {
+		if leaf {
+			return g.unsupported(val, "delimited arrays")
+		}
+		if g.isLevelEncoder(t) {
+			g.p("if err := %s.EncodeValuesLevel(%s, v, %s); err != nil {", val.expr, k.expr, levelExpr(val.depth))
+		} else {
+			g.p("if err := %s.EncodeValues(%s, v); err != nil {", val.expr, k.expr)
+		}
+		g.p("return qsgenError(%s, %s, %s, err)", k.expr, pathExpr(val.path), val.expr)
+		g.p("}")
+		return nil
+	}
+
+	// encoding.TextMarshaler
+	if hasValueMethod(t, val.addr, "MarshalText") {
+		// nil切片、map不输出，与反射实现一致
+		if isNilable(t) {
+			g.p("if %s != nil {", val.expr)
+		} else {
+			g.p("{")
+		}
+		g.p("text, err := %s.MarshalText()", val.expr)
+		g.p("if err != nil {")
+		g.p("return qsgenError(%s, %s, %s, err)", k.expr, pathExpr(val.path), val.expr)
+		g.p("}")
+		g.p("%s", out("string(text)"))
+		g.p("}")
+		return nil
+	}
+
+	// driver.Valuer，只支持sql.NullString等类型，Valid为false时不输出
+	if isValuer(t, val.addr) {
+		f, ok := nullField(t)
+		if !ok {
+			return g.unsupported(val, "driver.Valuer")
+		}
+		g.p("if %s.Valid {", val.expr)
+		elem := value{expr: val.expr + "." + f.Name(), typ: f.Type(), addr: val.addr, path: val.path, ff: val.ff, depth: val.depth}
+		var err error
+		if leaf {
+			err = g.encodeValue(elem, k, out)
+		} else {
+			err = g.encodeValue(elem, k, nil)
+		}
+		g.p("}")
+		return err
+	}
+
+	// 标签选项string开启时使用fmt.Stringer
+	if val.ff.stringer && hasValueMethod(t, val.addr, "String") {
+		if isNilable(t) {
+			g.p("if %s != nil {", val.expr)
+			g.p("%s", out(val.expr+".String()"))
+			g.p("}")
+		} else {
+			g.p("%s", out(val.expr+".String()"))
+		}
+		return nil
+	}
+
+	// 按格式作为单个值输出的字节切片、字节数组
+	isBytes, n, err := g.bytesOf(val)
+	if err != nil {
+		return err
+	}
+	if isBytes {
+		b := g.conv(types.NewSlice(types.Typ[types.Byte]), val.expr, val.typ)
+		if n >= 0 {
+			if n == 0 {
+				return nil
+			}
+			if !val.addr {
+				return g.unsupported(val, val.ff.bytes+" bytes")
+			}
+			b = val.expr + "[:]"
+		} else {
+			g.p("if len(%s) > 0 {", val.expr)
+		}
+		g.p("%s", out(fmt.Sprintf("qsgenEncodeBytes(%s, %s)", b, strconv.Quote(val.ff.bytes))))
+		if n < 0 {
+			g.p("}")
+		}
+		return nil
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Struct:
+		if leaf {
+			return g.unsupported(val, "delimited arrays")
+		}
+		return g.encodeStruct(val, k)
+	case *types.Slice, *types.Array:
+		if leaf {
+			return g.unsupported(val, "delimited arrays")
+		}
+		return g.encodeSlice(val, k)
+	case *types.Map:
+		if leaf {
+			return g.unsupported(val, "delimited arrays")
+		}
+		return g.encodeMap(val, k, u)
+	case *types.Basic:
+		s, ok := g.basicString(val, u)
+		if !ok {
+			return g.unsupported(val, "encoding")
+		}
+		g.p("%s", out(s))
+		return nil
+	}
+	return g.unsupported(val, "encoding")
+}
+
+// isNilable 类型是否为可能为nil的切片或map
+func isNilable(t types.Type) bool {
+	switch t.Underlying().(type) {
+	case *types.Slice, *types.Map:
+		return true
+	}
+	return false
+}
+
+// encodeStd 生成输出标准库类型的代码，值不可取地址时复制后调用指针上的方法
+func (g *generator) encodeStd(val value, k key, name string, out func(string) string) {
+	if name == "Duration" {
+		g.p("%s", out(fmt.Sprintf("qsgenFormatDuration(%s, %s)", val.expr, strconv.Quote(val.ff.duration))))
+		return
+	}
+	isText := name == "Int" || name == "Float" || name == "Rat"
+	if val.addr && !isText {
+		g.p("%s", out(val.expr+".String()"))
+		return
+	}
+	g.p("{")
+	e := val.expr
+	if !val.addr {
+		e = g.name("c")
+		g.p("%s := %s", e, val.expr)
+	}
+	if isText {
+		g.p("text, err := %s.MarshalText()", e)
+		g.p("if err != nil {")
+		g.p("return qsgenError(%s, %s, %s, err)", k.expr, pathExpr(val.path), e)
+		g.p("}")
+		g.p("%s", out("string(text)"))
+	} else {
+		g.p("%s", out(e+".String()"))
+	}
+	g.p("}")
+}
+
+// formatTime 按格式输出时间的表达式
+func (g *generator) formatTime(expr, layout string) string {
+	switch layout {
+	case "unix":
+		return fmt.Sprintf("strconv.FormatInt(%s.Unix(), 10)", expr)
+	case "unixmilli":
+		return fmt.Sprintf("strconv.FormatInt(%s.Unix()*1e3+int64(%s.Nanosecond())/1e6, 10)", expr, expr)
+	case "":
+		layout = defaultTimeLayout
+	}
+	return fmt.Sprintf("%s.Format(%s)", expr, strconv.Quote(layout))
+}
+
+// basicString 基础类型格式化为字符串的表达式，与fmt.Sprint的输出一致
+func (g *generator) basicString(val value, b *types.Basic) (string, bool) {
+	e := val.expr
+	// fmt.Sprint会使用这些方法
+	if hasMethod(val.typ, "String") || hasMethod(val.typ, "Error") || hasMethod(val.typ, "Format") {
+		return fmt.Sprintf("fmt.Sprint(%s)", e), true
+	}
+	info := b.Info()
+	switch {
+	case info&types.IsString != 0:
+		return g.conv(types.Typ[types.String], e, val.typ), true
+	case info&types.IsBoolean != 0 && val.ff.boolSet:
+		return fmt.Sprintf("qsgenBool(%s, %s, %s)", g.conv(types.Typ[types.Bool], e, val.typ), strconv.Quote(val.ff.boolT), strconv.Quote(val.ff.boolF)), true
+	case info&types.IsBoolean != 0:
+		return fmt.Sprintf("strconv.FormatBool(%s)", g.conv(types.Typ[types.Bool], e, val.typ)), true
+	case info&types.IsUnsigned != 0:
+		return fmt.Sprintf("strconv.FormatUint(%s, 10)", g.conv(types.Typ[types.Uint64], e, val.typ)), true
+	case info&types.IsInteger != 0:
+		return fmt.Sprintf("strconv.FormatInt(%s, 10)", g.conv(types.Typ[types.Int64], e, val.typ)), true
+	case info&types.IsFloat != 0:
+		return floatString(val, b), true
+	case info&types.IsComplex != 0:
+		return fmt.Sprintf("fmt.Sprint(%s)", e), true
+	}
+	return "", false
+}
+
+// floatString 浮点数按标签中的格式选项格式化的表达式，规则与query包一致
+func floatString(val value, b *types.Basic) string {
+	e, bits := "float64("+val.expr+")", 64
+	if b.Kind() == types.Float32 {
+		bits = 32
+	} else if types.Identical(val.typ, types.Typ[types.Float64]) {
+		e = val.expr
+	}
+	ff := val.ff
+	format := ff.floatFmt
+	if format == 0 {
+		if ff.decimal {
+			format = 'g'
+		} else {
+			return fmt.Sprintf("strconv.FormatFloat(%s, 'g', -1, %d)", e, bits)
+		}
+	}
+	s := fmt.Sprintf("strconv.FormatFloat(%s, %s, %d, %d)", e, strconv.QuoteRune(rune(format)), ff.prec, bits)
+	if ff.decimal && format != 'b' {
+		s = fmt.Sprintf("qsgenDecimal(%s)", s)
+	}
+	return s
+}
+
+// nonEmpty 值不为空的条件，规则与query包的omitempty一致，总是不为空时返回空字符串
+func nonEmpty(val value) string {
+	e := val.expr
+	switch u := val.typ.Underlying().(type) {
+	case *types.Array, *types.Slice, *types.Map:
+		return fmt.Sprintf("len(%s) != 0", e)
+	case *types.Pointer, *types.Interface:
+		return fmt.Sprintf("%s != nil", e)
+	case *types.Basic:
+		info := u.Info()
+		switch {
+		case info&types.IsString != 0:
+			return fmt.Sprintf("len(%s) != 0", e)
+		case info&types.IsBoolean != 0:
+			return e
+		case info&(types.IsInteger|types.IsFloat) != 0:
+			return fmt.Sprintf("%s != 0", e)
+		}
+	}
+	if hasMethod(val.typ, "IsZero") {
+		return fmt.Sprintf("!%s.IsZero()", e)
+	}
+	return ""
+}
+
+// omitCond 字段的忽略选项对应的输出条件，不需要判断时返回空字符串
+func (g *generator) omitCond(f field, val value) (string, error) {
+	var conds []string
+	_, isPtr := val.typ.Underlying().(*types.Pointer)
+	// nil指针本身不会输出
+	if f.omitEmpty && !isPtr {
+		if c := nonEmpty(val); c != "" {
+			conds = append(conds, c)
+		}
+	}
+	if f.omitZero {
+		c, err := g.nonZero(val)
+		if err != nil {
+			return "", err
+		}
+		if c != "" {
+			conds = append(conds, c)
+		}
+	}
+	if f.omitNil && !isPtr {
+		if c := nonNil(val); c != "" {
+			conds = append(conds, c)
+		}
+	}
+	return strings.Join(conds, " && "), nil
+}
+
+// nonZero 值不为零值的条件，规则与query包的omitzero一致
+func (g *generator) nonZero(val value) (string, error) {
+	e := val.expr
+	if _, ok := val.typ.Underlying().(*types.Pointer); ok {
+		if hasMethod(val.typ, "IsZero") {
+			return fmt.Sprintf("%s != nil && !%s.IsZero()", e, e), nil
+		}
+		// nil指针本身不会输出
+		return "", nil
+	}
+	if hasValueMethod(val.typ, val.addr, "IsZero") {
+		return fmt.Sprintf("!%s.IsZero()", e), nil
+	}
+	switch u := val.typ.Underlying().(type) {
+	case *types.Slice, *types.Map, *types.Interface, *types.Signature, *types.Chan:
+		return fmt.Sprintf("%s != nil", e), nil
+	case *types.Basic:
+		info := u.Info()
+		switch {
+		case info&types.IsString != 0:
+			return fmt.Sprintf("len(%s) != 0", e), nil
+		case info&types.IsBoolean != 0:
+			return e, nil
+		case info&types.IsFloat != 0:
+			// 与reflect.Value.IsZero一致，-0不是零值
+			return fmt.Sprintf("math.Float64bits(float64(%s)) != 0", e), nil
+		case info&types.IsNumeric != 0:
+			return fmt.Sprintf("%s != 0", e), nil
+		}
+	}
+	if !types.Comparable(val.typ) {
+		return "", fmt.Errorf("%s: omitzero is not supported for non-comparable type %s", strings.Trim(pathExpr(val.path), `"`), g.typeExpr(val.typ))
+	}
+	return fmt.Sprintf("%s != (%s{})", e, g.typeExpr(val.typ)), nil
+}
+
+// nonNil 值不为nil的条件，规则与query包的omitnil一致，不会为nil的类型返回空字符串
+func nonNil(val value) string {
+	switch val.typ.Underlying().(type) {
+	case *types.Pointer, *types.Slice, *types.Map, *types.Interface, *types.Signature, *types.Chan:
+		return fmt.Sprintf("%s != nil", val.expr)
+	}
+	return ""
+}
+
+// levelExpr 值在query包中的层数的表达式
+func levelExpr(depth int) string {
+	if depth == 0 {
+		return "level"
+	}
+	return "level+" + strconv.Itoa(depth)
+}
+
+// checkDepth 生成层数超过限制时返回错误的代码，与query包一致，nil指针等不输出的值也需要判断，
+// cond为值会被编码的条件，为空时总是判断
+func (g *generator) checkDepth(val value, k key, cond string) {
+	check := levelExpr(val.depth) + " > qsgenMaxLevel"
+	if cond != "" {
+		check = cond + " && " + check
+	}
+	g.p("if %s {", check)
+	g.p("return qsgenTooDeep(%s, %s, %s)", k.expr, pathExpr(val.path), val.expr)
+	g.p("}")
+}
+
+// reachCond 字段在omitCond之外还需满足的编码条件：指定了忽略选项时，
+// nil指针及Valid为false的sql.NullString等类型被忽略，不判断层数
+func reachCond(f field, val value) string {
+	if !f.omitEmpty && !f.omitZero && !f.omitNil {
+		return ""
+	}
+	if _, ok := val.typ.Underlying().(*types.Pointer); ok {
+		return val.expr + " != nil"
+	}
+	if (f.omitEmpty || f.omitNil) && isValuer(val.typ, val.addr) {
+		if _, ok := nullField(val.typ); ok {
+			return val.expr + ".Valid"
+		}
+	}
+	return ""
+}
+
+// encodeStruct 展开结构体的字段
+func (g *generator) encodeStruct(val value, k key) error {
+	if err := g.enter(val.typ); err != nil {
+		return err
+	}
+	defer g.leave()
+	fs, err := g.fields(val.typ.Underlying().(*types.Struct))
+	if err != nil {
+		return err
+	}
+	for _, f := range fs {
+		fv := value{expr: val.expr + "." + f.goName, typ: f.typ, addr: val.addr, path: val.path, ff: val.ff, depth: val.depth}
+		// 嵌套结构体使用上一层的参数名
+		if f.embedded {
+			if err := g.encodeValue(fv, k, nil); err != nil {
+				return err
+			}
+			continue
+		}
+		fv.path = fieldPath(val.path, f.goName)
+		fv.ff = f.ff
+		fv.depth++
+		cond, err := g.omitCond(f, fv)
+		if err != nil {
+			return err
+		}
+		if cond != "" {
+			g.p("if %s {", cond)
+		}
+		err = g.bind(childKey(k, f.name), func(fk key) error {
+			g.checkDepth(fv, fk, reachCond(f, fv))
+			return g.encodeValue(fv, fk, nil)
+		})
+		if err != nil {
+			return err
+		}
+		if cond != "" {
+			g.p("}")
+		}
+	}
+	return nil
+}
+
+// encodeSlice 编码数组、切片
+func (g *generator) encodeSlice(val value, k key) error {
+	var elem types.Type
+	addr := true
+	switch u := val.typ.Underlying().(type) {
+	case *types.Slice:
+		elem = u.Elem()
+	case *types.Array:
+		if u.Len() == 0 {
+			return nil
+		}
+		elem = u.Elem()
+		addr = val.addr
+	}
+	if k.top {
+		return g.unsupported(val, "encoding")
+	}
+	i := g.name("i")
+	ev := value{expr: val.expr + "[" + i + "]", typ: elem, addr: addr, path: indexPath(val.path, "strconv.Itoa("+i+")"), ff: val.ff, depth: val.depth + 1}
+
+	if val.ff.arrayFormat == "delimited" {
+		parts := g.name("parts")
+		body, err := g.capture(func() error {
+			g.checkDepth(ev, k, "")
+			return g.encodeValue(ev, k, func(s string) string {
+				return fmt.Sprintf("%s = append(%s, %s)", parts, parts, s)
+			})
+		})
+		if err != nil {
+			return err
+		}
+		if _, ok := val.typ.Underlying().(*types.Slice); ok {
+			g.p("if len(%s) > 0 {", val.expr)
+		} else {
+			g.p("{")
+		}
+		g.p("%s := make([]string, 0, len(%s))", parts, val.expr)
+		g.p("for %s := range %s {", i, val.expr)
+		g.buf.WriteString(body)
+		g.p("}")
+		g.p("if len(%s) > 0 {", parts)
+		g.p("v.Add(%s, strings.Join(%s, %s))", k.expr, parts, strconv.Quote(val.ff.delimiter))
+		g.p("}")
+		g.p("}")
+		return nil
+	}
+
+	var ek key
+	switch val.ff.arrayFormat {
+	case "brackets":
+		ek = key{expr: appendLit(k.expr, "[]")}
+	case "repeat":
+		ek = k
+	default:
+		ek = dynamicKey(k, "strconv.Itoa("+i+")")
+	}
+	body, err := g.capture(func() error {
+		g.checkDepth(ev, ek, "")
+		return g.encodeValue(ev, ek, nil)
+	})
+	if err != nil || strings.TrimSpace(body) == "" {
+		return err
+	}
+	g.p("for %s := range %s {", i, val.expr)
+	g.buf.WriteString(body)
+	g.p("}")
+	return nil
+}
+
+// encodeMap 编码map，key的规则与query包一致
+func (g *generator) encodeMap(val value, k key, m *types.Map) error {
+	if k.top {
+		return g.unsupported(val, "encoding")
+	}
+	mk, mv := g.name("mk"), g.name("mv")
+
+	// key格式化为参数名
+	var keyCode []string
+	ks := ""
+	kt := m.Key()
+	b, isBasic := kt.Underlying().(*types.Basic)
+	switch {
+	case isBasic && b.Info()&types.IsString != 0:
+		ks = g.conv(types.Typ[types.String], mk, kt)
+	case hasMethod(kt, "MarshalText"):
+		if _, ok := kt.Underlying().(*types.Pointer); ok {
+			return g.unsupported(value{typ: kt, path: val.path}, "map keys")
+		}
+		ks = g.name("ks")
+		keyCode = []string{
+			fmt.Sprintf("text, err := %s.MarshalText()", mk),
+			"if err != nil {",
+			fmt.Sprintf("return qsgenError(%s, %s, %s, err)", k.expr, pathExpr(val.path), val.expr),
+			"}",
+			fmt.Sprintf("%s := string(text)", ks),
+		}
+	case isBasic && b.Info()&types.IsBoolean != 0:
+		ks = fmt.Sprintf("strconv.FormatBool(%s)", g.conv(types.Typ[types.Bool], mk, kt))
+	case isBasic && b.Info()&types.IsUnsigned != 0:
+		ks = fmt.Sprintf("strconv.FormatUint(%s, 10)", g.conv(types.Typ[types.Uint64], mk, kt))
+	case isBasic && b.Info()&types.IsInteger != 0:
+		ks = fmt.Sprintf("strconv.FormatInt(%s, 10)", g.conv(types.Typ[types.Int64], mk, kt))
+	default:
+		return g.unsupported(value{typ: kt, path: val.path}, "map keys")
+	}
+	if !isIdent(ks) && len(keyCode) == 0 {
+		name := g.name("ks")
+		keyCode = []string{fmt.Sprintf("%s := %s", name, ks)}
+		ks = name
+	}
+
+	ev := value{expr: mv, typ: m.Elem(), path: indexPath(val.path, "strconv.Quote("+ks+")"), ff: val.ff, depth: val.depth + 1}
+	body, err := g.capture(func() error {
+		ek := dynamicKey(k, ks)
+		g.checkDepth(ev, ek, "")
+		return g.encodeValue(ev, ek, nil)
+	})
+	if err != nil || strings.TrimSpace(body) == "" {
+		return err
+	}
+	g.p("for %s, %s := range %s {", mk, mv, val.expr)
+	for _, line := range keyCode {
+		g.p("%s", line)
+	}
+	g.buf.WriteString(body)
+	g.p("}")
+	return nil
+}
@@ -0,0 +1,633 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/types"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// queryPath query包的导入路径
+const queryPath = "github.com/rumis/querystring/query"
+
+// defaultTimeLayout 与query包的默认时间格式一致
+const defaultTimeLayout = "2006-01-02 15:04:05"
+
+// generator 生成代码的状态
+type generator struct {
+	cfg     config
+	pkg     *types.Package
+	objs    []*types.TypeName
+	targets map[*types.TypeName]bool
+	// 引用的其他包，导入路径对应包名
+	imports map[string]string
+	buf     *bytes.Buffer
+	// 局部变量的序号
+	n int
+	// 正在展开的结构体，用于检测引用自身的类型
+	stack []types.Type
+}
+
+// newGenerator 创建生成器
+func newGenerator(pkg *types.Package, objs []*types.TypeName, cfg config) *generator {
+	g := &generator{
+		cfg:     cfg,
+		pkg:     pkg,
+		objs:    objs,
+		targets: make(map[*types.TypeName]bool),
+		imports: make(map[string]string),
+		buf:     new(bytes.Buffer),
+	}
+	for _, obj := range objs {
+		g.targets[obj] = true
+	}
+	return g
+}
+
+// value 生成代码中的值
+type value struct {
+	// 取值的表达式，如 x.Filter.Tags
+	expr string
+	typ  types.Type
+	// 可取地址，可以使用指针接收者的方法
+	addr bool
+	// Go字段路径的表达式，用于错误信息
+	path string
+	ff   fieldFormat
+	// 相对于方法接收者的嵌套层数，与level相加后为query包中的层数
+	depth int
+}
+
+// key 生成代码中参数名的表达式，top表示方法的key参数本身，子参数需按是否为空拼接
+type key struct {
+	expr string
+	top  bool
+}
+
+var topKey = key{expr: "key", top: true}
+
+// fieldFormat 字段标签中的格式选项，规则与query包一致
+type fieldFormat struct {
+	// indices、brackets、repeat、delimited，为空时使用indices
+	arrayFormat string
+	delimiter   string
+	// 时间格式，可以是unix、unixmilli，为空时使用默认格式
+	timeLayout string
+	stringer   bool
+	// 浮点数的格式，floatFmt为0时与fmt.Sprint一致
+	floatFmt byte
+	prec     int
+	decimal  bool
+	// 布尔值的输出形式，boolSet为false时输出 true、false
+	boolSet      bool
+	boolT, boolF string
+	// 字节切片、字节数组的编码格式，如 base64、hex，为空时每个字节作为一个元素
+	bytes string
+	// time.Duration的单位，seconds、ms，为空时使用time.Duration.String的格式
+	duration string
+}
+
+var delimiters = map[string]string{
+	"comma": ",",
+	"space": " ",
+	"pipe":  "|",
+}
+
+var bytesFormats = map[string]bool{
+	"base64":       true,
+	"base64url":    true,
+	"base64raw":    true,
+	"base64urlraw": true,
+	"hex":          true,
+	"raw":          true,
+}
+
+var timeLayouts = map[string]string{
+	"unix":        "unix",
+	"unixmilli":   "unixmilli",
+	"rfc3339":     time.RFC3339,
+	"rfc3339nano": time.RFC3339Nano,
+}
+
+// parseFormat 解析标签选项中的格式选项
+func parseFormat(opts []string) fieldFormat {
+	ff := fieldFormat{prec: -1}
+	for _, opt := range opts {
+		switch {
+		case strings.HasPrefix(opt, "prec="):
+			n, err := strconv.Atoi(strings.TrimPrefix(opt, "prec="))
+			if err != nil || n < 0 {
+				continue
+			}
+			ff.prec = n
+			if ff.floatFmt == 0 {
+				ff.floatFmt = 'f'
+			}
+		case bytesFormats[opt]:
+			ff.bytes = opt
+		case opt == "seconds" || opt == "ms":
+			ff.duration = opt
+		case opt == "int":
+			ff.boolSet, ff.boolT, ff.boolF = true, "1", "0"
+		case strings.HasPrefix(opt, "bool="):
+			parts := strings.SplitN(strings.TrimPrefix(opt, "bool="), "|", 2)
+			if len(parts) == 2 && parts[0] != "" {
+				ff.boolSet, ff.boolT, ff.boolF = true, parts[0], parts[1]
+			}
+		case opt == "fixed":
+			ff.floatFmt = 'f'
+		case opt == "decimal":
+			ff.decimal = true
+		case strings.HasPrefix(opt, "fmt=") && len(opt) == len("fmt=")+1:
+			ff.floatFmt = opt[len(opt)-1]
+		case opt == "indices" || opt == "brackets" || opt == "repeat":
+			ff.arrayFormat = opt
+		case delimiters[opt] != "":
+			ff.arrayFormat = "delimited"
+			ff.delimiter = delimiters[opt]
+		case strings.HasPrefix(opt, "del="):
+			ff.arrayFormat = "delimited"
+			ff.delimiter = strings.TrimPrefix(opt, "del=")
+		case timeLayouts[opt] != "":
+			ff.timeLayout = timeLayouts[opt]
+		case strings.HasPrefix(opt, "layout="):
+			ff.timeLayout = strings.TrimPrefix(opt, "layout=")
+		case opt == "string":
+			ff.stringer = true
+		}
+	}
+	return ff
+}
+
+// field 结构体字段，规则与query包解析字段的规则一致
+type field struct {
+	goName    string
+	name      string
+	typ       types.Type
+	embedded  bool
+	omitEmpty bool
+	omitZero  bool
+	omitNil   bool
+	ff        fieldFormat
+}
+
+// fields 返回结构体参与编解码的字段
+func (g *generator) fields(st *types.Struct) ([]field, error) {
+	var fs []field
+	for i := 0; i < st.NumFields(); i++ {
+		sf := st.Field(i)
+		// 私有字段
+		if !sf.Exported() && !sf.Embedded() {
+			continue
+		}
+		tag := reflect.StructTag(st.Tag(i)).Get(g.cfg.tagKey)
+		if tag == "-" {
+			continue
+		}
+		base := sf.Type()
+		if p, ok := base.Underlying().(*types.Pointer); ok {
+			base = p.Elem()
+		}
+		// 嵌套结构体
+		if sf.Embedded() {
+			if _, ok := base.Underlying().(*types.Struct); ok {
+				if !sf.Exported() && sf.Pkg() != g.pkg {
+					return nil, fmt.Errorf("cannot access embedded field %s of package %s", sf.Name(), sf.Pkg().Path())
+				}
+				fs = append(fs, field{goName: sf.Name(), typ: sf.Type(), embedded: true})
+				continue
+			}
+			if !sf.Exported() {
+				continue
+			}
+		}
+		parts := strings.Split(tag, ",")
+		name, opts := parts[0], parts[1:]
+		if name == "" {
+			name = sf.Name()
+		}
+		f := field{goName: sf.Name(), name: name, typ: sf.Type(), ff: parseFormat(opts)}
+		if f.ff.floatFmt != 0 && !strings.ContainsRune("beEfgGxX", rune(f.ff.floatFmt)) {
+			return nil, fmt.Errorf("%s: invalid float format %q", sf.Name(), f.ff.floatFmt)
+		}
+		// false不输出时需要跳过参数，生成的代码不支持
+		if f.ff.boolSet && f.ff.boolF == "" {
+			return nil, fmt.Errorf("%s: bool format without a false value is not supported", sf.Name())
+		}
+		for _, opt := range opts {
+			switch opt {
+			case "omitempty":
+				f.omitEmpty = true
+			case "omitzero":
+				f.omitZero = true
+			case "omitnil":
+				f.omitNil = true
+			case "omitempty=deep":
+				// 需要先编码再判断是否输出，生成的代码不支持
+				return nil, fmt.Errorf("%s: option %q is not supported", sf.Name(), opt)
+			}
+			// 生成的DecodeValues不执行校验规则、不使用默认值，避免与反射解码的行为不一致
+			if g.cfg.decode && isDecodeOption(opt) {
+				return nil, fmt.Errorf("%s: option %q is not supported with -decode", sf.Name(), opt)
+			}
+		}
+		fs = append(fs, f)
+	}
+	return fs, nil
+}
+
+// isDecodeOption 是否为query包解码时的校验规则或默认值
+func isDecodeOption(opt string) bool {
+	if opt == "required" {
+		return true
+	}
+	for _, prefix := range []string{"min=", "max=", "len=", "oneof=", "pattern=", "default="} {
+		if strings.HasPrefix(opt, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// p 输出一行代码
+func (g *generator) p(format string, args ...interface{}) {
+	fmt.Fprintf(g.buf, format, args...)
+	g.buf.WriteByte('\n')
+}
+
+// name 返回不重复的局部变量名
+func (g *generator) name(prefix string) string {
+	g.n++
+	return prefix + strconv.Itoa(g.n)
+}
+
+// capture 将f生成的代码写入单独的缓冲区并返回
+func (g *generator) capture(f func() error) (string, error) {
+	saved := g.buf
+	g.buf = new(bytes.Buffer)
+	err := f()
+	out := g.buf.String()
+	g.buf = saved
+	return out, err
+}
+
+// bind 使用局部变量保存参数名，仅被引用一次且不在循环中时直接使用表达式
+func (g *generator) bind(k key, f func(key) error) error {
+	if k.top || isIdent(k.expr) {
+		return f(k)
+	}
+	name := g.name("k")
+	body, err := g.capture(func() error { return f(key{expr: name}) })
+	if err != nil {
+		return err
+	}
+	re := regexp.MustCompile(`\b` + name + `\b`)
+	switch n := len(re.FindAllStringIndex(body, -1)); {
+	case n == 0:
+	case n == 1 && !strings.Contains(body, "for "):
+		body = re.ReplaceAllLiteralString(body, k.expr)
+	default:
+		g.p("%s := %s", name, k.expr)
+	}
+	g.buf.WriteString(body)
+	return nil
+}
+
+var identRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func isIdent(s string) bool {
+	return identRe.MatchString(s)
+}
+
+// childKey 子参数名的表达式
+func childKey(k key, name string) key {
+	if k.top {
+		return key{expr: fmt.Sprintf("qsgenKey(key, %s)", strconv.Quote(name))}
+	}
+	return key{expr: appendLit(k.expr, "["+name+"]")}
+}
+
+// dynamicKey 子参数名由表达式dyn生成的参数名，如数组下标、map的key
+func dynamicKey(k key, dyn string) key {
+	return key{expr: appendLit(appendLit(k.expr, "[")+" + "+dyn, "]")}
+}
+
+// appendLit 在字符串表达式后追加常量，表达式以常量结尾时合并为一个常量
+func appendLit(expr, s string) string {
+	q := strconv.Quote(s)
+	if expr == "" || expr == `""` {
+		return q
+	}
+	if strings.HasSuffix(expr, `"`) {
+		return expr[:len(expr)-1] + q[1:]
+	}
+	return expr + " + " + q
+}
+
+// fieldPath 在Go字段路径后追加字段名
+func fieldPath(path, name string) string {
+	if path == "" {
+		return strconv.Quote(name)
+	}
+	return appendLit(path, "."+name)
+}
+
+// indexPath 在Go字段路径后追加由表达式dyn生成的下标
+func indexPath(path, dyn string) string {
+	return appendLit(appendLit(path, "[")+" + "+dyn, "]")
+}
+
+// pathExpr 路径为空时使用空字符串
+func pathExpr(path string) string {
+	if path == "" {
+		return `""`
+	}
+	return path
+}
+
+// typeExpr 类型在生成代码中的写法
+func (g *generator) typeExpr(t types.Type) string {
+	return types.TypeString(t, func(p *types.Package) string {
+		if p == g.pkg {
+			return ""
+		}
+		g.imports[p.Path()] = p.Name()
+		return p.Name()
+	})
+}
+
+// conv 将表达式转换为类型t，类型相同时不转换
+func (g *generator) conv(t types.Type, expr string, from types.Type) string {
+	if types.Identical(t, from) {
+		return expr
+	}
+	return g.typeExpr(t) + "(" + expr + ")"
+}
+
+// bytesOf 按标签中的格式作为单个值编解码的字节切片、字节数组，返回数组的长度，切片为-1，
+// 元素为以uint8为底层类型的自定义类型时返回错误
+func (g *generator) bytesOf(val value) (bool, int64, error) {
+	if val.ff.bytes == "" {
+		return false, 0, nil
+	}
+	var elem types.Type
+	n := int64(-1)
+	switch u := val.typ.Underlying().(type) {
+	case *types.Slice:
+		elem = u.Elem()
+	case *types.Array:
+		elem, n = u.Elem(), u.Len()
+	default:
+		return false, 0, nil
+	}
+	b, ok := elem.Underlying().(*types.Basic)
+	if !ok || b.Kind() != types.Uint8 {
+		return false, 0, nil
+	}
+	if !types.Identical(elem, types.Typ[types.Byte]) {
+		return false, 0, g.unsupported(val, val.ff.bytes+" bytes")
+	}
+	return true, n, nil
+}
+
+// stdTypes 作为单个值编解码的标准库类型，与query包一致，包路径对应类型名
+var stdTypes = map[string][]string{
+	"time":     {"Duration", "Location"},
+	"net/url":  {"URL"},
+	"net":      {"IPNet"},
+	"regexp":   {"Regexp"},
+	"math/big": {"Int", "Float", "Rat"},
+}
+
+// stdType 返回标准库类型的类型名，其他类型返回空字符串
+func stdType(t types.Type) string {
+	n, ok := t.(*types.Named)
+	if !ok || n.Obj().Pkg() == nil {
+		return ""
+	}
+	for _, name := range stdTypes[n.Obj().Pkg().Path()] {
+		if n.Obj().Name() == name {
+			return name
+		}
+	}
+	return ""
+}
+
+// isTime 是否为time.Time
+func isTime(t types.Type) bool {
+	n, ok := t.(*types.Named)
+	return ok && n.Obj().Pkg() != nil && n.Obj().Pkg().Path() == "time" && n.Obj().Name() == "Time"
+}
+
+// hasMethod 类型的方法集是否包含方法
+func hasMethod(t types.Type, name string) bool {
+	return types.NewMethodSet(t).Lookup(nil, name) != nil
+}
+
+// hasValueMethod 值的方法集包含方法，可取地址时包括指针的方法集
+func hasValueMethod(t types.Type, addr bool, name string) bool {
+	return hasMethod(t, name) || (addr && hasMethod(types.NewPointer(t), name))
+}
+
+// isValuer 值的方法集包含driver.Valuer的Value方法
+func isValuer(t types.Type, addr bool) bool {
+	ms := types.NewMethodSet(t)
+	if addr && ms.Lookup(nil, "Value") == nil {
+		ms = types.NewMethodSet(types.NewPointer(t))
+	}
+	sel := ms.Lookup(nil, "Value")
+	if sel == nil {
+		return false
+	}
+	sig := sel.Obj().Type().(*types.Signature)
+	if sig.Params().Len() != 0 || sig.Results().Len() != 2 {
+		return false
+	}
+	n, ok := sig.Results().At(0).Type().(*types.Named)
+	return ok && n.Obj().Pkg() != nil && n.Obj().Pkg().Path() == "database/sql/driver" && n.Obj().Name() == "Value" &&
+		types.Identical(sig.Results().At(1).Type(), types.Universe.Lookup("error").Type())
+}
+
+// nullField 返回sql.NullString等类型的值字段，这些类型由值字段及表示非NULL的Valid字段组成，规则与query包一致
+func nullField(t types.Type) (*types.Var, bool) {
+	st, ok := t.Underlying().(*types.Struct)
+	if !ok || st.NumFields() != 2 {
+		return nil, false
+	}
+	v, valid := st.Field(0), st.Field(1)
+	b, ok := valid.Type().Underlying().(*types.Basic)
+	return v, v.Exported() && valid.Name() == "Valid" && ok && b.Kind() == types.Bool
+}
+
+// isTarget 是否为本次生成方法的类型
+func (g *generator) isTarget(t types.Type) bool {
+	n, ok := t.(*types.Named)
+	return ok && g.targets[n.Obj()]
+}
+
+// isLevelEncoder 类型的Encode方法可以接收嵌套层数
+func (g *generator) isLevelEncoder(t types.Type) bool {
+	return g.isTarget(t) || hasMethod(t, "EncodeValuesLevel")
+}
+
+// isEncoder 类型实现了query.Encoder
+func (g *generator) isEncoder(t types.Type) bool {
+	return g.isTarget(t) || hasMethod(t, "EncodeValues")
+}
+
+// isDecoder 类型的指针实现了query.Decoder
+func (g *generator) isDecoder(t types.Type) bool {
+	return (g.cfg.decode && g.isTarget(t)) || hasMethod(types.NewPointer(t), "DecodeValues")
+}
+
+// enter 记录正在展开的结构体，类型引用自身时返回错误
+func (g *generator) enter(t types.Type) error {
+	for _, s := range g.stack {
+		if types.Identical(s, t) {
+			return fmt.Errorf("recursive type %s must be generated too", g.typeExpr(t))
+		}
+	}
+	g.stack = append(g.stack, t)
+	return nil
+}
+
+func (g *generator) leave() {
+	g.stack = g.stack[:len(g.stack)-1]
+}
+
+// unsupported 不支持的类型
+func (g *generator) unsupported(val value, what string) error {
+	return fmt.Errorf("%s: unsupported type %s for %s", strings.Trim(pathExpr(val.path), `"`), g.typeExpr(val.typ), what)
+}
+
+// generate 生成方法
+func (g *generator) generate() ([]byte, error) {
+	body, err := g.capture(func() error {
+		for _, obj := range g.objs {
+			if err := g.genType(obj); err != nil {
+				return fmt.Errorf("%s: %v", obj.Name(), err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return g.file(body+helperSource(body), g.imports)
+}
+
+// genType 生成单个类型的方法
+func (g *generator) genType(obj *types.TypeName) error {
+	t := obj.Type()
+	if hasMethod(t, "EncodeValues") || hasMethod(types.NewPointer(t), "EncodeValues") {
+		return fmt.Errorf("type already has an EncodeValues method")
+	}
+	root := value{expr: "x", typ: t, addr: true}
+
+	g.n = 0
+	g.p("// EncodeValues 实现query.Encoder，输出的参数与query.Values一致")
+	g.p("func (x %s) EncodeValues(key string, v *url.Values) error {", obj.Name())
+	g.p("return x.EncodeValuesLevel(key, v, 1)")
+	g.p("}")
+	g.p("")
+	g.p("// EncodeValuesLevel 与EncodeValues相同，level为x所在的嵌套层数，超过query包默认的最大层数时返回错误")
+	g.p("func (x %s) EncodeValuesLevel(key string, v *url.Values, level int) error {", obj.Name())
+	if err := g.encodeStruct(root, topKey); err != nil {
+		return err
+	}
+	g.p("return nil")
+	g.p("}")
+	g.p("")
+
+	if !g.cfg.decode {
+		return nil
+	}
+	if hasMethod(types.NewPointer(t), "DecodeValues") {
+		return fmt.Errorf("type already has a DecodeValues method")
+	}
+	g.n = 0
+	g.p("// DecodeValues 实现query.Decoder，解码规则与query.Unmarshal一致")
+	g.p("func (x *%s) DecodeValues(key string, values url.Values) error {", obj.Name())
+	if err := g.decodeStruct(root, topKey); err != nil {
+		return err
+	}
+	g.p("return nil")
+	g.p("}")
+	g.p("")
+	return nil
+}
+
+// stdImports 生成代码可能用到的标准库及query包，包名对应导入路径
+var stdImports = map[string]string{
+	"base64":  "encoding/base64",
+	"big":     "math/big",
+	"errors":  "errors",
+	"fmt":     "fmt",
+	"hex":     "encoding/hex",
+	"math":    "math",
+	"net":     "net",
+	"rand":    "math/rand",
+	"reflect": "reflect",
+	"regexp":  "regexp",
+	"sort":    "sort",
+	"strconv": "strconv",
+	"strings": "strings",
+	"testing": "testing",
+	"time":    "time",
+	"url":     "net/url",
+	"query":   queryPath,
+}
+
+var pkgRefRe = regexp.MustCompile(`(?:^|[^.\w])([a-z][a-z0-9]*)\.[A-Za-z]`)
+var stringLitRe = regexp.MustCompile("\"(?:[^\"\\\\\n]|\\\\.)*\"|`[^`]*`")
+
+// file 组合生成的代码，根据代码中引用的包及imports中的导入路径生成import并格式化
+func (g *generator) file(body string, imports map[string]string) ([]byte, error) {
+	paths := make(map[string]bool)
+	for _, m := range pkgRefRe.FindAllStringSubmatch(stringLitRe.ReplaceAllString(body, `""`), -1) {
+		if path, ok := stdImports[m[1]]; ok {
+			paths[path] = true
+		}
+	}
+	for path := range imports {
+		paths[path] = true
+	}
+	var std, other []string
+	for path := range paths {
+		if strings.Contains(strings.SplitN(path, "/", 2)[0], ".") {
+			other = append(other, path)
+		} else {
+			std = append(std, path)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by qsgen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", g.pkg.Name())
+	buf.WriteString("import (\n")
+	for _, path := range std {
+		fmt.Fprintf(&buf, "%q\n", path)
+	}
+	if len(std) > 0 && len(other) > 0 {
+		buf.WriteString("\n")
+	}
+	for _, path := range other {
+		fmt.Fprintf(&buf, "%q\n", path)
+	}
+	buf.WriteString(")\n\n")
+	buf.WriteString(body)
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %v\n%s", err, buf.Bytes())
+	}
+	return src, nil
+}
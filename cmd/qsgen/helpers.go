@@ -0,0 +1,315 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// helper 生成代码中使用的辅助函数，每个包只生成一份
+type helper struct {
+	name string
+	src  string
+}
+
+var helpers = []helper{
+	{"qsgenKey", `
+// qsgenKey 拼接参数名，与query包默认的中括号格式一致
+func qsgenKey(key, name string) string {
+	if key == "" {
+		return name
+	}
+	return key + "[" + name + "]"
+}
+`},
+	{"qsgenError", `
+// qsgenError 将编码错误包装为*query.EncodeError
+func qsgenError(key, field string, v interface{}, err error) error {
+	var ee *query.EncodeError
+	if errors.As(err, &ee) {
+		return err
+	}
+	return &query.EncodeError{Key: key, Field: field, Type: reflect.TypeOf(v), Err: err}
+}
+`},
+	{"qsgenTooDeep", `
+// qsgenMaxLevel 与query包默认的最大嵌套层数一致
+const qsgenMaxLevel = 7
+
+// qsgenTooDeep 超过最大嵌套层数时返回的错误，与query包一致
+func qsgenTooDeep(key, field string, v interface{}) error {
+	return qsgenError(key, field, v, fmt.Errorf("%w, the max is: %v", query.ErrTooDeep, qsgenMaxLevel))
+}
+`},
+	{"qsgenDecimal", `
+// qsgenDecimal 浮点数不包含小数点时补充 .0，与query包的decimal选项一致
+func qsgenDecimal(s string) string {
+	if strings.ContainsAny(s, ".IN") {
+		return s
+	}
+	if i := strings.IndexAny(s, "eEpP"); i >= 0 {
+		return s[:i] + ".0" + s[i:]
+	}
+	return s + ".0"
+}
+`},
+	{"qsgenBool", `
+// qsgenBool 按标签中的int、bool=选项输出布尔值
+func qsgenBool(b bool, t, f string) string {
+	if b {
+		return t
+	}
+	return f
+}
+`},
+	{"qsgenParseBool", `
+// qsgenParseBool 先匹配标签中指定的值，再按strconv.ParseBool解析
+func qsgenParseBool(s, t, f string) (bool, error) {
+	switch s {
+	case t:
+		return true, nil
+	case f:
+		return false, nil
+	}
+	return strconv.ParseBool(s)
+}
+`},
+	{"qsgenEncodeBytes", `
+// qsgenEncodeBytes 按标签中的格式编码字节，与query包的BytesFormat一致
+func qsgenEncodeBytes(b []byte, format string) string {
+	switch format {
+	case "base64":
+		return base64.StdEncoding.EncodeToString(b)
+	case "base64url":
+		return base64.URLEncoding.EncodeToString(b)
+	case "base64raw":
+		return base64.RawStdEncoding.EncodeToString(b)
+	case "base64urlraw":
+		return base64.RawURLEncoding.EncodeToString(b)
+	case "hex":
+		return hex.EncodeToString(b)
+	}
+	return string(b)
+}
+`},
+	{"qsgenDecodeBytes", `
+// qsgenDecodeBytes 按标签中的格式解码字节，与query包的BytesFormat一致
+func qsgenDecodeBytes(s, format string) ([]byte, error) {
+	switch format {
+	case "base64":
+		return base64.StdEncoding.DecodeString(s)
+	case "base64url":
+		return base64.URLEncoding.DecodeString(s)
+	case "base64raw":
+		return base64.RawStdEncoding.DecodeString(s)
+	case "base64urlraw":
+		return base64.RawURLEncoding.DecodeString(s)
+	case "hex":
+		return hex.DecodeString(s)
+	}
+	return []byte(s), nil
+}
+`},
+	{"qsgenValue", `
+// qsgenValue 返回参数的第一个值
+func qsgenValue(values url.Values, key string) (string, bool) {
+	vs := values[key]
+	if len(vs) == 0 {
+		return "", false
+	}
+	return vs[0], true
+}
+`},
+	{"qsgenHas", `
+// qsgenHas 是否存在key或以key为前缀的参数
+func qsgenHas(values url.Values, key string) bool {
+	if _, ok := values[key]; ok {
+		return true
+	}
+	for k := range values {
+		if _, ok := qsgenChild(k, key); ok {
+			return true
+		}
+	}
+	return false
+}
+`},
+	{"qsgenChildren", `
+// qsgenChildren 返回key下一层的参数名并排序，如 key[a]、key[b][c] 返回 a、b
+func qsgenChildren(values url.Values, key string) []string {
+	var segs []string
+	seen := make(map[string]bool)
+	for k := range values {
+		if seg, ok := qsgenChild(k, key); ok && !seen[seg] {
+			seen[seg] = true
+			segs = append(segs, seg)
+		}
+	}
+	sort.Strings(segs)
+	return segs
+}
+`},
+	{"qsgenChild", `
+// qsgenChild 返回参数名k在key下一层的名称，k的剩余部分需为 [a][b] 的格式
+func qsgenChild(k, key string) (string, bool) {
+	if !strings.HasPrefix(k, key+"[") {
+		return "", false
+	}
+	rest := k[len(key):]
+	seg := ""
+	for first := true; len(rest) > 0; first = false {
+		j := strings.IndexByte(rest, ']')
+		if rest[0] != '[' || j < 0 {
+			return "", false
+		}
+		if first {
+			seg = rest[1:j]
+		}
+		rest = rest[j+1:]
+	}
+	return seg, true
+}
+`},
+	{"qsgenElements", `
+// qsgenElements 返回key对应的数组元素，规则与query包一致：元素按下标放置，缺失的下标为nil，
+// 没有下标的重复参数及空中括号的参数按出现顺序作为元素，指定分隔符时对每个值进行拆分，
+// length为数组的长度，切片为-1，切片缺失的下标最多为query包的默认值100
+func qsgenElements(values url.Values, key, delimiter string, length int) ([][]string, error) {
+	segs := qsgenChildren(values, key)
+	var elems [][]string
+	switch {
+	case len(segs) == 0:
+		elems = qsgenSplit(values[key], delimiter)
+	case len(segs) == 1 && segs[0] == "" && len(qsgenChildren(values, key+"[]")) == 0:
+		elems = qsgenSplit(values[key+"[]"], delimiter)
+	default:
+		indexes := make([]int, len(segs))
+		max := -1
+		for i, seg := range segs {
+			n, err := strconv.Atoi(seg)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid index: %q", seg)
+			}
+			indexes[i] = n
+			if n > max {
+				max = n
+			}
+		}
+		if length < 0 && max+1-len(segs) > 100 {
+			return nil, fmt.Errorf("index %d leaves %d missing elements, the max is: 100", max, max+1-len(segs))
+		}
+		if length >= 0 && max >= length {
+			return nil, fmt.Errorf("index %d out of range for array of length %d", max, length)
+		}
+		elems = make([][]string, max+1)
+		for i, seg := range segs {
+			elems[indexes[i]] = values[key+"["+seg+"]"]
+		}
+		return elems, nil
+	}
+	if length >= 0 && len(elems) > length {
+		return nil, fmt.Errorf("index %d out of range for array of length %d", len(elems)-1, length)
+	}
+	return elems, nil
+}
+`},
+	{"qsgenSplit", `
+// qsgenSplit 每个值作为一个元素，指定分隔符时对值进行拆分
+func qsgenSplit(vs []string, delimiter string) [][]string {
+	var elems [][]string
+	for _, s := range vs {
+		if delimiter == "" {
+			elems = append(elems, []string{s})
+			continue
+		}
+		for _, part := range strings.Split(s, delimiter) {
+			elems = append(elems, []string{part})
+		}
+	}
+	return elems
+}
+`},
+	{"qsgenFormatDuration", `
+// qsgenFormatDuration 按单位输出时长，与query包的seconds、ms选项一致
+func qsgenFormatDuration(d time.Duration, unit string) string {
+	switch unit {
+	case "seconds":
+		return strconv.FormatFloat(float64(d)/float64(time.Second), 'f', -1, 64)
+	case "ms":
+		return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', -1, 64)
+	}
+	return d.String()
+}
+`},
+	{"qsgenParseDuration", `
+// qsgenParseDuration 按单位解析时长，未指定单位时也接受以纳秒为单位的整数
+func qsgenParseDuration(s, unit string) (time.Duration, error) {
+	if unit == "seconds" || unit == "ms" {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, err
+		}
+		if unit == "seconds" {
+			return time.Duration(math.Round(f * float64(time.Second))), nil
+		}
+		return time.Duration(math.Round(f * float64(time.Millisecond))), nil
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Duration(n), nil
+	}
+	return time.ParseDuration(s)
+}
+`},
+	{"qsgenParseIPNet", `
+// qsgenParseIPNet 解析CIDR格式的网络并保留主机地址，与query包一致
+func qsgenParseIPNet(s string) (*net.IPNet, error) {
+	ip, n, err := net.ParseCIDR(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(n.IP) == net.IPv4len {
+		ip = ip.To4()
+	}
+	return &net.IPNet{IP: ip, Mask: n.Mask}, nil
+}
+`},
+	{"qsgenParseTime", `
+// qsgenParseTime 按格式解析时间，时间戳解析为UTC时间
+func qsgenParseTime(s, layout string) (time.Time, error) {
+	switch layout {
+	case "unix", "unixmilli":
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if layout == "unix" {
+			return time.Unix(n, 0).UTC(), nil
+		}
+		return time.Unix(n/1e3, n%1e3*1e6).UTC(), nil
+	}
+	return time.Parse(layout, s)
+}
+`},
+}
+
+// helperSource 返回code中用到的辅助函数，包括辅助函数之间的引用
+func helperSource(code string) string {
+	used := make([]bool, len(helpers))
+	all := code
+	for changed := true; changed; {
+		changed = false
+		for i, h := range helpers {
+			if !used[i] && regexp.MustCompile(`\b`+h.name+`\(`).MatchString(all) {
+				used[i] = true
+				all += h.src
+				changed = true
+			}
+		}
+	}
+	var b strings.Builder
+	for i, h := range helpers {
+		if used[i] {
+			b.WriteString(h.src)
+		}
+	}
+	return b.String()
+}
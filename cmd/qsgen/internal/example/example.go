@@ -0,0 +1,122 @@
+// Package example 演示qsgen生成的代码，生成的测试验证其与反射编解码的结果一致
+package example
+
+import (
+	"database/sql"
+	"math/big"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+//go:generate go run github.com/rumis/querystring/cmd/qsgen -decode -test
+
+// Level 通过encoding.TextMarshaler输出名称的枚举
+type Level int
+
+var levelNames = []string{"low", "mid", "high"}
+
+// MarshalText 输出级别的名称，未定义名称的级别输出数字
+func (l Level) MarshalText() ([]byte, error) {
+	if l >= 0 && int(l) < len(levelNames) {
+		return []byte(levelNames[l]), nil
+	}
+	return []byte(strconv.Itoa(int(l))), nil
+}
+
+// UnmarshalText 解析级别的名称或数字
+func (l *Level) UnmarshalText(text []byte) error {
+	for i, name := range levelNames {
+		if name == string(text) {
+			*l = Level(i)
+			return nil
+		}
+	}
+	n, err := strconv.Atoi(string(text))
+	if err != nil {
+		return err
+	}
+	*l = Level(n)
+	return nil
+}
+
+// Page 分页参数
+type Page struct {
+	Page int `qs:"page,omitempty"`
+	Size int `qs:"size,omitempty"`
+}
+
+// Filter 过滤条件
+type Filter struct {
+	Tags   []string   `qs:"tags,comma"`
+	Levels []Level    `qs:"levels,repeat"`
+	Since  *time.Time `qs:"since,unix,omitempty"`
+}
+
+// Options 查询参数
+//
+//qs:generate
+type Options struct {
+	Page
+	Query   string            `qs:"q"`
+	All     bool              `qs:"all,omitempty"`
+	Score   float64           `qs:"score"`
+	Ratio   float32           `qs:"ratio,omitempty"`
+	Count   uint16            `qs:"count"`
+	IDs     []int64           `qs:"ids"`
+	Sort    [2]string         `qs:"sort,brackets"`
+	Level   Level             `qs:"level"`
+	Filter  Filter            `qs:"filter"`
+	Extra   *Filter           `qs:"extra"`
+	Labels  map[string]string `qs:"labels"`
+	Weights map[int]float64   `qs:"weights"`
+	Counts  map[Level]uint    `qs:"counts"`
+	Created time.Time         `qs:"created"`
+	Day     time.Time         `qs:"day,layout=2006-01-02"`
+	Root    *Node             `qs:"root"`
+	Offset  int8              `qs:"offset,omitzero"`
+	Limit   *int              `qs:"limit,omitzero"`
+	Window  Page              `qs:"window,omitzero"`
+	Until   time.Time         `qs:"until,unix,omitzero"`
+	Notes   []string          `qs:"notes,omitnil"`
+	Price   float64           `qs:"price,prec=2"`
+	Lat     float32           `qs:"lat,fixed,decimal"`
+	Rates   []float64         `qs:"rates,comma,fmt=e,prec=3"`
+	Total   float64           `qs:"total,decimal"`
+	Legacy  bool              `qs:"legacy,int"`
+	Flags   map[string]bool   `qs:"flags,bool=yes|no"`
+	Sig     []byte            `qs:"sig,base64url"`
+	Hash    [4]byte           `qs:"hash,hex"`
+	Keys    [][]byte          `qs:"keys,base64raw"`
+	Raw     []byte            `qs:"raw,raw"`
+	Timeout time.Duration     `qs:"timeout,ms"`
+	TTL     *time.Duration    `qs:"ttl,seconds"`
+	Retries []time.Duration   `qs:"retries,comma"`
+	Home    url.URL           `qs:"home"`
+	Proxy   *url.URL          `qs:"proxy"`
+	Addr    net.IP            `qs:"addr"`
+	Subnet  *net.IPNet        `qs:"subnet"`
+	Zone    *time.Location    `qs:"zone"`
+	Match   *regexp.Regexp    `qs:"match"`
+	Amount  big.Int           `qs:"amount"`
+	Share   *big.Rat          `qs:"share"`
+	Nick    sql.NullString    `qs:"nick"`
+	Age     *sql.NullInt64    `qs:"age"`
+	Seen    sql.NullTime      `qs:"seen,unix"`
+	Scores  []sql.NullFloat64 `qs:"scores,comma"`
+	Skip    string            `qs:"-"`
+	ignored string
+}
+
+// Node 引用自身的类型需要同时生成
+//
+//qs:generate
+type Node struct {
+	Name     string  `qs:"name"`
+	Weight   *int    `qs:"weight"`
+	Children []Node  `qs:"children"`
+	Parent   *Node   `qs:"parent"`
+	Days     []uint8 `qs:"days,pipe"`
+}
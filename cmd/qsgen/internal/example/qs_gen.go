@@ -0,0 +1,1508 @@
+// Code generated by qsgen. DO NOT EDIT.
+
+package example
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rumis/querystring/query"
+)
+
+// EncodeValues 实现query.Encoder，输出的参数与query.Values一致
+func (x Options) EncodeValues(key string, v *url.Values) error {
+	return x.EncodeValuesLevel(key, v, 1)
+}
+
+// EncodeValuesLevel 与EncodeValues相同，level为x所在的嵌套层数，超过query包默认的最大层数时返回错误
+func (x Options) EncodeValuesLevel(key string, v *url.Values, level int) error {
+	if x.Page.Page != 0 {
+		k1 := qsgenKey(key, "page")
+		if level+1 > qsgenMaxLevel {
+			return qsgenTooDeep(k1, "Page", x.Page.Page)
+		}
+		v.Add(k1, strconv.FormatInt(int64(x.Page.Page), 10))
+	}
+	if x.Page.Size != 0 {
+		k2 := qsgenKey(key, "size")
+		if level+1 > qsgenMaxLevel {
+			return qsgenTooDeep(k2, "Size", x.Page.Size)
+		}
+		v.Add(k2, strconv.FormatInt(int64(x.Page.Size), 10))
+	}
+	k3 := qsgenKey(key, "q")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k3, "Query", x.Query)
+	}
+	v.Add(k3, x.Query)
+	if x.All {
+		k4 := qsgenKey(key, "all")
+		if level+1 > qsgenMaxLevel {
+			return qsgenTooDeep(k4, "All", x.All)
+		}
+		v.Add(k4, strconv.FormatBool(x.All))
+	}
+	k5 := qsgenKey(key, "score")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k5, "Score", x.Score)
+	}
+	v.Add(k5, strconv.FormatFloat(x.Score, 'g', -1, 64))
+	if x.Ratio != 0 {
+		k6 := qsgenKey(key, "ratio")
+		if level+1 > qsgenMaxLevel {
+			return qsgenTooDeep(k6, "Ratio", x.Ratio)
+		}
+		v.Add(k6, strconv.FormatFloat(float64(x.Ratio), 'g', -1, 32))
+	}
+	k7 := qsgenKey(key, "count")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k7, "Count", x.Count)
+	}
+	v.Add(k7, strconv.FormatUint(uint64(x.Count), 10))
+	k8 := qsgenKey(key, "ids")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k8, "IDs", x.IDs)
+	}
+	for i9 := range x.IDs {
+		if level+2 > qsgenMaxLevel {
+			return qsgenTooDeep(k8+"["+strconv.Itoa(i9)+"]", "IDs["+strconv.Itoa(i9)+"]", x.IDs[i9])
+		}
+		v.Add(k8+"["+strconv.Itoa(i9)+"]", strconv.FormatInt(x.IDs[i9], 10))
+	}
+	k10 := qsgenKey(key, "sort")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k10, "Sort", x.Sort)
+	}
+	for i11 := range x.Sort {
+		if level+2 > qsgenMaxLevel {
+			return qsgenTooDeep(k10+"[]", "Sort["+strconv.Itoa(i11)+"]", x.Sort[i11])
+		}
+		v.Add(k10+"[]", x.Sort[i11])
+	}
+	k12 := qsgenKey(key, "level")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k12, "Level", x.Level)
+	}
+	{
+		text, err := x.Level.MarshalText()
+		if err != nil {
+			return qsgenError(k12, "Level", x.Level, err)
+		}
+		v.Add(k12, string(text))
+	}
+	k13 := qsgenKey(key, "filter")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k13, "Filter", x.Filter)
+	}
+	k14 := k13 + "[tags]"
+	if level+2 > qsgenMaxLevel {
+		return qsgenTooDeep(k14, "Filter.Tags", x.Filter.Tags)
+	}
+	if len(x.Filter.Tags) > 0 {
+		parts16 := make([]string, 0, len(x.Filter.Tags))
+		for i15 := range x.Filter.Tags {
+			if level+3 > qsgenMaxLevel {
+				return qsgenTooDeep(k14, "Filter.Tags["+strconv.Itoa(i15)+"]", x.Filter.Tags[i15])
+			}
+			parts16 = append(parts16, x.Filter.Tags[i15])
+		}
+		if len(parts16) > 0 {
+			v.Add(k14, strings.Join(parts16, ","))
+		}
+	}
+	k17 := k13 + "[levels]"
+	if level+2 > qsgenMaxLevel {
+		return qsgenTooDeep(k17, "Filter.Levels", x.Filter.Levels)
+	}
+	for i18 := range x.Filter.Levels {
+		if level+3 > qsgenMaxLevel {
+			return qsgenTooDeep(k17, "Filter.Levels["+strconv.Itoa(i18)+"]", x.Filter.Levels[i18])
+		}
+		{
+			text, err := x.Filter.Levels[i18].MarshalText()
+			if err != nil {
+				return qsgenError(k17, "Filter.Levels["+strconv.Itoa(i18)+"]", x.Filter.Levels[i18], err)
+			}
+			v.Add(k17, string(text))
+		}
+	}
+	k19 := k13 + "[since]"
+	if x.Filter.Since != nil && level+2 > qsgenMaxLevel {
+		return qsgenTooDeep(k19, "Filter.Since", x.Filter.Since)
+	}
+	if x.Filter.Since != nil {
+		v.Add(k19, strconv.FormatInt((*x.Filter.Since).Unix(), 10))
+	}
+	k20 := qsgenKey(key, "extra")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k20, "Extra", x.Extra)
+	}
+	if x.Extra != nil {
+		k21 := k20 + "[tags]"
+		if level+2 > qsgenMaxLevel {
+			return qsgenTooDeep(k21, "Extra.Tags", (*x.Extra).Tags)
+		}
+		if len((*x.Extra).Tags) > 0 {
+			parts23 := make([]string, 0, len((*x.Extra).Tags))
+			for i22 := range (*x.Extra).Tags {
+				if level+3 > qsgenMaxLevel {
+					return qsgenTooDeep(k21, "Extra.Tags["+strconv.Itoa(i22)+"]", (*x.Extra).Tags[i22])
+				}
+				parts23 = append(parts23, (*x.Extra).Tags[i22])
+			}
+			if len(parts23) > 0 {
+				v.Add(k21, strings.Join(parts23, ","))
+			}
+		}
+		k24 := k20 + "[levels]"
+		if level+2 > qsgenMaxLevel {
+			return qsgenTooDeep(k24, "Extra.Levels", (*x.Extra).Levels)
+		}
+		for i25 := range (*x.Extra).Levels {
+			if level+3 > qsgenMaxLevel {
+				return qsgenTooDeep(k24, "Extra.Levels["+strconv.Itoa(i25)+"]", (*x.Extra).Levels[i25])
+			}
+			{
+				text, err := (*x.Extra).Levels[i25].MarshalText()
+				if err != nil {
+					return qsgenError(k24, "Extra.Levels["+strconv.Itoa(i25)+"]", (*x.Extra).Levels[i25], err)
+				}
+				v.Add(k24, string(text))
+			}
+		}
+		k26 := k20 + "[since]"
+		if (*x.Extra).Since != nil && level+2 > qsgenMaxLevel {
+			return qsgenTooDeep(k26, "Extra.Since", (*x.Extra).Since)
+		}
+		if (*x.Extra).Since != nil {
+			v.Add(k26, strconv.FormatInt((*(*x.Extra).Since).Unix(), 10))
+		}
+	}
+	k27 := qsgenKey(key, "labels")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k27, "Labels", x.Labels)
+	}
+	for mk28, mv29 := range x.Labels {
+		if level+2 > qsgenMaxLevel {
+			return qsgenTooDeep(k27+"["+mk28+"]", "Labels["+strconv.Quote(mk28)+"]", mv29)
+		}
+		v.Add(k27+"["+mk28+"]", mv29)
+	}
+	k30 := qsgenKey(key, "weights")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k30, "Weights", x.Weights)
+	}
+	for mk31, mv32 := range x.Weights {
+		ks33 := strconv.FormatInt(int64(mk31), 10)
+		if level+2 > qsgenMaxLevel {
+			return qsgenTooDeep(k30+"["+ks33+"]", "Weights["+strconv.Quote(ks33)+"]", mv32)
+		}
+		v.Add(k30+"["+ks33+"]", strconv.FormatFloat(mv32, 'g', -1, 64))
+	}
+	k34 := qsgenKey(key, "counts")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k34, "Counts", x.Counts)
+	}
+	for mk35, mv36 := range x.Counts {
+		text, err := mk35.MarshalText()
+		if err != nil {
+			return qsgenError(k34, "Counts", x.Counts, err)
+		}
+		ks37 := string(text)
+		if level+2 > qsgenMaxLevel {
+			return qsgenTooDeep(k34+"["+ks37+"]", "Counts["+strconv.Quote(ks37)+"]", mv36)
+		}
+		v.Add(k34+"["+ks37+"]", strconv.FormatUint(uint64(mv36), 10))
+	}
+	k38 := qsgenKey(key, "created")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k38, "Created", x.Created)
+	}
+	v.Add(k38, x.Created.Format("2006-01-02 15:04:05"))
+	k39 := qsgenKey(key, "day")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k39, "Day", x.Day)
+	}
+	v.Add(k39, x.Day.Format("2006-01-02"))
+	k40 := qsgenKey(key, "root")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k40, "Root", x.Root)
+	}
+	if x.Root != nil {
+		if err := (*x.Root).EncodeValuesLevel(k40, v, level+1); err != nil {
+			return qsgenError(k40, "Root", (*x.Root), err)
+		}
+	}
+	if x.Offset != 0 {
+		k41 := qsgenKey(key, "offset")
+		if level+1 > qsgenMaxLevel {
+			return qsgenTooDeep(k41, "Offset", x.Offset)
+		}
+		v.Add(k41, strconv.FormatInt(int64(x.Offset), 10))
+	}
+	k42 := qsgenKey(key, "limit")
+	if x.Limit != nil && level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k42, "Limit", x.Limit)
+	}
+	if x.Limit != nil {
+		v.Add(k42, strconv.FormatInt(int64((*x.Limit)), 10))
+	}
+	if x.Window != (Page{}) {
+		k43 := qsgenKey(key, "window")
+		if level+1 > qsgenMaxLevel {
+			return qsgenTooDeep(k43, "Window", x.Window)
+		}
+		if x.Window.Page != 0 {
+			k44 := k43 + "[page]"
+			if level+2 > qsgenMaxLevel {
+				return qsgenTooDeep(k44, "Window.Page", x.Window.Page)
+			}
+			v.Add(k44, strconv.FormatInt(int64(x.Window.Page), 10))
+		}
+		if x.Window.Size != 0 {
+			k45 := k43 + "[size]"
+			if level+2 > qsgenMaxLevel {
+				return qsgenTooDeep(k45, "Window.Size", x.Window.Size)
+			}
+			v.Add(k45, strconv.FormatInt(int64(x.Window.Size), 10))
+		}
+	}
+	if !x.Until.IsZero() {
+		k46 := qsgenKey(key, "until")
+		if level+1 > qsgenMaxLevel {
+			return qsgenTooDeep(k46, "Until", x.Until)
+		}
+		v.Add(k46, strconv.FormatInt(x.Until.Unix(), 10))
+	}
+	if x.Notes != nil {
+		k47 := qsgenKey(key, "notes")
+		if level+1 > qsgenMaxLevel {
+			return qsgenTooDeep(k47, "Notes", x.Notes)
+		}
+		for i48 := range x.Notes {
+			if level+2 > qsgenMaxLevel {
+				return qsgenTooDeep(k47+"["+strconv.Itoa(i48)+"]", "Notes["+strconv.Itoa(i48)+"]", x.Notes[i48])
+			}
+			v.Add(k47+"["+strconv.Itoa(i48)+"]", x.Notes[i48])
+		}
+	}
+	k49 := qsgenKey(key, "price")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k49, "Price", x.Price)
+	}
+	v.Add(k49, strconv.FormatFloat(x.Price, 'f', 2, 64))
+	k50 := qsgenKey(key, "lat")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k50, "Lat", x.Lat)
+	}
+	v.Add(k50, qsgenDecimal(strconv.FormatFloat(float64(x.Lat), 'f', -1, 32)))
+	k51 := qsgenKey(key, "rates")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k51, "Rates", x.Rates)
+	}
+	if len(x.Rates) > 0 {
+		parts53 := make([]string, 0, len(x.Rates))
+		for i52 := range x.Rates {
+			if level+2 > qsgenMaxLevel {
+				return qsgenTooDeep(k51, "Rates["+strconv.Itoa(i52)+"]", x.Rates[i52])
+			}
+			parts53 = append(parts53, strconv.FormatFloat(x.Rates[i52], 'e', 3, 64))
+		}
+		if len(parts53) > 0 {
+			v.Add(k51, strings.Join(parts53, ","))
+		}
+	}
+	k54 := qsgenKey(key, "total")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k54, "Total", x.Total)
+	}
+	v.Add(k54, qsgenDecimal(strconv.FormatFloat(x.Total, 'g', -1, 64)))
+	k55 := qsgenKey(key, "legacy")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k55, "Legacy", x.Legacy)
+	}
+	v.Add(k55, qsgenBool(x.Legacy, "1", "0"))
+	k56 := qsgenKey(key, "flags")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k56, "Flags", x.Flags)
+	}
+	for mk57, mv58 := range x.Flags {
+		if level+2 > qsgenMaxLevel {
+			return qsgenTooDeep(k56+"["+mk57+"]", "Flags["+strconv.Quote(mk57)+"]", mv58)
+		}
+		v.Add(k56+"["+mk57+"]", qsgenBool(mv58, "yes", "no"))
+	}
+	k59 := qsgenKey(key, "sig")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k59, "Sig", x.Sig)
+	}
+	if len(x.Sig) > 0 {
+		v.Add(k59, qsgenEncodeBytes(x.Sig, "base64url"))
+	}
+	k60 := qsgenKey(key, "hash")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k60, "Hash", x.Hash)
+	}
+	v.Add(k60, qsgenEncodeBytes(x.Hash[:], "hex"))
+	k61 := qsgenKey(key, "keys")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k61, "Keys", x.Keys)
+	}
+	for i62 := range x.Keys {
+		if level+2 > qsgenMaxLevel {
+			return qsgenTooDeep(k61+"["+strconv.Itoa(i62)+"]", "Keys["+strconv.Itoa(i62)+"]", x.Keys[i62])
+		}
+		if len(x.Keys[i62]) > 0 {
+			v.Add(k61+"["+strconv.Itoa(i62)+"]", qsgenEncodeBytes(x.Keys[i62], "base64raw"))
+		}
+	}
+	k63 := qsgenKey(key, "raw")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k63, "Raw", x.Raw)
+	}
+	if len(x.Raw) > 0 {
+		v.Add(k63, qsgenEncodeBytes(x.Raw, "raw"))
+	}
+	k64 := qsgenKey(key, "timeout")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k64, "Timeout", x.Timeout)
+	}
+	v.Add(k64, qsgenFormatDuration(x.Timeout, "ms"))
+	k65 := qsgenKey(key, "ttl")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k65, "TTL", x.TTL)
+	}
+	if x.TTL != nil {
+		v.Add(k65, qsgenFormatDuration((*x.TTL), "seconds"))
+	}
+	k66 := qsgenKey(key, "retries")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k66, "Retries", x.Retries)
+	}
+	if len(x.Retries) > 0 {
+		parts68 := make([]string, 0, len(x.Retries))
+		for i67 := range x.Retries {
+			if level+2 > qsgenMaxLevel {
+				return qsgenTooDeep(k66, "Retries["+strconv.Itoa(i67)+"]", x.Retries[i67])
+			}
+			parts68 = append(parts68, qsgenFormatDuration(x.Retries[i67], ""))
+		}
+		if len(parts68) > 0 {
+			v.Add(k66, strings.Join(parts68, ","))
+		}
+	}
+	k69 := qsgenKey(key, "home")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k69, "Home", x.Home)
+	}
+	v.Add(k69, x.Home.String())
+	k70 := qsgenKey(key, "proxy")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k70, "Proxy", x.Proxy)
+	}
+	if x.Proxy != nil {
+		v.Add(k70, (*x.Proxy).String())
+	}
+	k71 := qsgenKey(key, "addr")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k71, "Addr", x.Addr)
+	}
+	if x.Addr != nil {
+		text, err := x.Addr.MarshalText()
+		if err != nil {
+			return qsgenError(k71, "Addr", x.Addr, err)
+		}
+		v.Add(k71, string(text))
+	}
+	k72 := qsgenKey(key, "subnet")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k72, "Subnet", x.Subnet)
+	}
+	if x.Subnet != nil {
+		v.Add(k72, (*x.Subnet).String())
+	}
+	k73 := qsgenKey(key, "zone")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k73, "Zone", x.Zone)
+	}
+	if x.Zone != nil {
+		v.Add(k73, (*x.Zone).String())
+	}
+	k74 := qsgenKey(key, "match")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k74, "Match", x.Match)
+	}
+	if x.Match != nil {
+		v.Add(k74, (*x.Match).String())
+	}
+	k75 := qsgenKey(key, "amount")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k75, "Amount", x.Amount)
+	}
+	{
+		text, err := x.Amount.MarshalText()
+		if err != nil {
+			return qsgenError(k75, "Amount", x.Amount, err)
+		}
+		v.Add(k75, string(text))
+	}
+	k76 := qsgenKey(key, "share")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k76, "Share", x.Share)
+	}
+	if x.Share != nil {
+		{
+			text, err := (*x.Share).MarshalText()
+			if err != nil {
+				return qsgenError(k76, "Share", (*x.Share), err)
+			}
+			v.Add(k76, string(text))
+		}
+	}
+	k77 := qsgenKey(key, "nick")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k77, "Nick", x.Nick)
+	}
+	if x.Nick.Valid {
+		v.Add(k77, x.Nick.String)
+	}
+	k78 := qsgenKey(key, "age")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k78, "Age", x.Age)
+	}
+	if x.Age != nil {
+		if (*x.Age).Valid {
+			v.Add(k78, strconv.FormatInt((*x.Age).Int64, 10))
+		}
+	}
+	k79 := qsgenKey(key, "seen")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k79, "Seen", x.Seen)
+	}
+	if x.Seen.Valid {
+		v.Add(k79, strconv.FormatInt(x.Seen.Time.Unix(), 10))
+	}
+	k80 := qsgenKey(key, "scores")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k80, "Scores", x.Scores)
+	}
+	if len(x.Scores) > 0 {
+		parts82 := make([]string, 0, len(x.Scores))
+		for i81 := range x.Scores {
+			if level+2 > qsgenMaxLevel {
+				return qsgenTooDeep(k80, "Scores["+strconv.Itoa(i81)+"]", x.Scores[i81])
+			}
+			if x.Scores[i81].Valid {
+				parts82 = append(parts82, strconv.FormatFloat(x.Scores[i81].Float64, 'g', -1, 64))
+			}
+		}
+		if len(parts82) > 0 {
+			v.Add(k80, strings.Join(parts82, ","))
+		}
+	}
+	return nil
+}
+
+// DecodeValues 实现query.Decoder，解码规则与query.Unmarshal一致
+func (x *Options) DecodeValues(key string, values url.Values) error {
+	k1 := qsgenKey(key, "page")
+	if s2, ok := qsgenValue(values, k1); ok {
+		n3, err := strconv.ParseInt(s2, 10, strconv.IntSize)
+		if err != nil {
+			return fmt.Errorf("invalid int value of %s: %q", k1, s2)
+		}
+		x.Page.Page = int(n3)
+	}
+	k4 := qsgenKey(key, "size")
+	if s5, ok := qsgenValue(values, k4); ok {
+		n6, err := strconv.ParseInt(s5, 10, strconv.IntSize)
+		if err != nil {
+			return fmt.Errorf("invalid int value of %s: %q", k4, s5)
+		}
+		x.Page.Size = int(n6)
+	}
+	if s8, ok := qsgenValue(values, qsgenKey(key, "q")); ok {
+		x.Query = s8
+	}
+	k9 := qsgenKey(key, "all")
+	if s10, ok := qsgenValue(values, k9); ok {
+		n11, err := strconv.ParseBool(s10)
+		if err != nil {
+			return fmt.Errorf("invalid bool value of %s: %q", k9, s10)
+		}
+		x.All = n11
+	}
+	k12 := qsgenKey(key, "score")
+	if s13, ok := qsgenValue(values, k12); ok {
+		n14, err := strconv.ParseFloat(s13, 64)
+		if err != nil {
+			return fmt.Errorf("invalid float value of %s: %q", k12, s13)
+		}
+		x.Score = n14
+	}
+	k15 := qsgenKey(key, "ratio")
+	if s16, ok := qsgenValue(values, k15); ok {
+		n17, err := strconv.ParseFloat(s16, 32)
+		if err != nil {
+			return fmt.Errorf("invalid float value of %s: %q", k15, s16)
+		}
+		x.Ratio = float32(n17)
+	}
+	k18 := qsgenKey(key, "count")
+	if s19, ok := qsgenValue(values, k18); ok {
+		n20, err := strconv.ParseUint(s19, 10, 16)
+		if err != nil {
+			return fmt.Errorf("invalid uint value of %s: %q", k18, s19)
+		}
+		x.Count = uint16(n20)
+	}
+	k21 := qsgenKey(key, "ids")
+	{
+		e22, err := qsgenElements(values, k21, "", -1)
+		if err != nil {
+			return fmt.Errorf("%s: %v", k21, err)
+		}
+		if len(e22) > 0 {
+			s25 := make([]int64, len(e22))
+			for i23, vs24 := range e22 {
+				if len(vs24) > 0 {
+					n26, err := strconv.ParseInt(vs24[0], 10, 64)
+					if err != nil {
+						return fmt.Errorf("invalid int value of %s: %q", k21+"["+strconv.Itoa(i23)+"]", vs24[0])
+					}
+					s25[i23] = n26
+				}
+			}
+			x.IDs = s25
+		}
+	}
+	k27 := qsgenKey(key, "sort")
+	{
+		e28, err := qsgenElements(values, k27, "", 2)
+		if err != nil {
+			return fmt.Errorf("%s: %v", k27, err)
+		}
+		for i29, vs30 := range e28 {
+			if len(vs30) > 0 {
+				x.Sort[i29] = vs30[0]
+			}
+		}
+	}
+	k31 := qsgenKey(key, "level")
+	if s32, ok := qsgenValue(values, k31); ok {
+		if err := x.Level.UnmarshalText([]byte(s32)); err != nil {
+			return fmt.Errorf("invalid value of %s: %v", k31, err)
+		}
+	}
+	k33 := qsgenKey(key, "filter")
+	k34 := k33 + "[tags]"
+	{
+		e35, err := qsgenElements(values, k34, ",", -1)
+		if err != nil {
+			return fmt.Errorf("%s: %v", k34, err)
+		}
+		if len(e35) > 0 {
+			s38 := make([]string, len(e35))
+			for i36, vs37 := range e35 {
+				if len(vs37) > 0 {
+					s38[i36] = vs37[0]
+				}
+			}
+			x.Filter.Tags = s38
+		}
+	}
+	k39 := k33 + "[levels]"
+	{
+		e40, err := qsgenElements(values, k39, "", -1)
+		if err != nil {
+			return fmt.Errorf("%s: %v", k39, err)
+		}
+		if len(e40) > 0 {
+			s43 := make([]Level, len(e40))
+			for i41, vs42 := range e40 {
+				if len(vs42) > 0 {
+					if err := s43[i41].UnmarshalText([]byte(vs42[0])); err != nil {
+						return fmt.Errorf("invalid value of %s: %v", k39+"["+strconv.Itoa(i41)+"]", err)
+					}
+				}
+			}
+			x.Filter.Levels = s43
+		}
+	}
+	k44 := k33 + "[since]"
+	if qsgenHas(values, k44) {
+		if x.Filter.Since == nil {
+			x.Filter.Since = new(time.Time)
+		}
+		if s45, ok := qsgenValue(values, k44); ok {
+			t46, err := qsgenParseTime(s45, "unix")
+			if err != nil {
+				return fmt.Errorf("invalid time value of %s: %v", k44, err)
+			}
+			(*x.Filter.Since) = t46
+		}
+	}
+	k47 := qsgenKey(key, "extra")
+	if qsgenHas(values, k47) {
+		if x.Extra == nil {
+			x.Extra = new(Filter)
+		}
+		k48 := k47 + "[tags]"
+		{
+			e49, err := qsgenElements(values, k48, ",", -1)
+			if err != nil {
+				return fmt.Errorf("%s: %v", k48, err)
+			}
+			if len(e49) > 0 {
+				s52 := make([]string, len(e49))
+				for i50, vs51 := range e49 {
+					if len(vs51) > 0 {
+						s52[i50] = vs51[0]
+					}
+				}
+				(*x.Extra).Tags = s52
+			}
+		}
+		k53 := k47 + "[levels]"
+		{
+			e54, err := qsgenElements(values, k53, "", -1)
+			if err != nil {
+				return fmt.Errorf("%s: %v", k53, err)
+			}
+			if len(e54) > 0 {
+				s57 := make([]Level, len(e54))
+				for i55, vs56 := range e54 {
+					if len(vs56) > 0 {
+						if err := s57[i55].UnmarshalText([]byte(vs56[0])); err != nil {
+							return fmt.Errorf("invalid value of %s: %v", k53+"["+strconv.Itoa(i55)+"]", err)
+						}
+					}
+				}
+				(*x.Extra).Levels = s57
+			}
+		}
+		k58 := k47 + "[since]"
+		if qsgenHas(values, k58) {
+			if (*x.Extra).Since == nil {
+				(*x.Extra).Since = new(time.Time)
+			}
+			if s59, ok := qsgenValue(values, k58); ok {
+				t60, err := qsgenParseTime(s59, "unix")
+				if err != nil {
+					return fmt.Errorf("invalid time value of %s: %v", k58, err)
+				}
+				(*(*x.Extra).Since) = t60
+			}
+		}
+	}
+	k61 := qsgenKey(key, "labels")
+	if c62 := qsgenChildren(values, k61); len(c62) > 0 {
+		if x.Labels == nil {
+			x.Labels = make(map[string]string, len(c62))
+		}
+		for _, seg63 := range c62 {
+			var mv65 string
+			vs64 := values[k61+"["+seg63+"]"]
+			if len(vs64) > 0 {
+				mv65 = vs64[0]
+			}
+			x.Labels[seg63] = mv65
+		}
+	}
+	k67 := qsgenKey(key, "weights")
+	if c68 := qsgenChildren(values, k67); len(c68) > 0 {
+		if x.Weights == nil {
+			x.Weights = make(map[int]float64, len(c68))
+		}
+		for _, seg69 := range c68 {
+			var mv71 float64
+			vs70 := values[k67+"["+seg69+"]"]
+			if len(vs70) > 0 {
+				n73, err := strconv.ParseFloat(vs70[0], 64)
+				if err != nil {
+					return fmt.Errorf("invalid float value of %s: %q", k67+"["+seg69+"]", vs70[0])
+				}
+				mv71 = n73
+			}
+			mk72, err := strconv.ParseInt(seg69, 10, strconv.IntSize)
+			if err != nil {
+				return fmt.Errorf("invalid map key of %s: %v", k67, err)
+			}
+			x.Weights[int(mk72)] = mv71
+		}
+	}
+	k74 := qsgenKey(key, "counts")
+	if c75 := qsgenChildren(values, k74); len(c75) > 0 {
+		if x.Counts == nil {
+			x.Counts = make(map[Level]uint, len(c75))
+		}
+		for _, seg76 := range c75 {
+			var mv78 uint
+			vs77 := values[k74+"["+seg76+"]"]
+			if len(vs77) > 0 {
+				n80, err := strconv.ParseUint(vs77[0], 10, strconv.IntSize)
+				if err != nil {
+					return fmt.Errorf("invalid uint value of %s: %q", k74+"["+seg76+"]", vs77[0])
+				}
+				mv78 = uint(n80)
+			}
+			var mk79 Level
+			if err := mk79.UnmarshalText([]byte(seg76)); err != nil {
+				return fmt.Errorf("invalid map key of %s: %v", k74, err)
+			}
+			x.Counts[mk79] = mv78
+		}
+	}
+	k81 := qsgenKey(key, "created")
+	if s82, ok := qsgenValue(values, k81); ok {
+		t83, err := qsgenParseTime(s82, "2006-01-02 15:04:05")
+		if err != nil {
+			return fmt.Errorf("invalid time value of %s: %v", k81, err)
+		}
+		x.Created = t83
+	}
+	k84 := qsgenKey(key, "day")
+	if s85, ok := qsgenValue(values, k84); ok {
+		t86, err := qsgenParseTime(s85, "2006-01-02")
+		if err != nil {
+			return fmt.Errorf("invalid time value of %s: %v", k84, err)
+		}
+		x.Day = t86
+	}
+	k87 := qsgenKey(key, "root")
+	if qsgenHas(values, k87) {
+		if x.Root == nil {
+			x.Root = new(Node)
+		}
+		if err := (*x.Root).DecodeValues(k87, values); err != nil {
+			return err
+		}
+	}
+	k88 := qsgenKey(key, "offset")
+	if s89, ok := qsgenValue(values, k88); ok {
+		n90, err := strconv.ParseInt(s89, 10, 8)
+		if err != nil {
+			return fmt.Errorf("invalid int value of %s: %q", k88, s89)
+		}
+		x.Offset = int8(n90)
+	}
+	k91 := qsgenKey(key, "limit")
+	if qsgenHas(values, k91) {
+		if x.Limit == nil {
+			x.Limit = new(int)
+		}
+		if s92, ok := qsgenValue(values, k91); ok {
+			n93, err := strconv.ParseInt(s92, 10, strconv.IntSize)
+			if err != nil {
+				return fmt.Errorf("invalid int value of %s: %q", k91, s92)
+			}
+			(*x.Limit) = int(n93)
+		}
+	}
+	k94 := qsgenKey(key, "window")
+	k95 := k94 + "[page]"
+	if s96, ok := qsgenValue(values, k95); ok {
+		n97, err := strconv.ParseInt(s96, 10, strconv.IntSize)
+		if err != nil {
+			return fmt.Errorf("invalid int value of %s: %q", k95, s96)
+		}
+		x.Window.Page = int(n97)
+	}
+	k98 := k94 + "[size]"
+	if s99, ok := qsgenValue(values, k98); ok {
+		n100, err := strconv.ParseInt(s99, 10, strconv.IntSize)
+		if err != nil {
+			return fmt.Errorf("invalid int value of %s: %q", k98, s99)
+		}
+		x.Window.Size = int(n100)
+	}
+	k101 := qsgenKey(key, "until")
+	if s102, ok := qsgenValue(values, k101); ok {
+		t103, err := qsgenParseTime(s102, "unix")
+		if err != nil {
+			return fmt.Errorf("invalid time value of %s: %v", k101, err)
+		}
+		x.Until = t103
+	}
+	k104 := qsgenKey(key, "notes")
+	{
+		e105, err := qsgenElements(values, k104, "", -1)
+		if err != nil {
+			return fmt.Errorf("%s: %v", k104, err)
+		}
+		if len(e105) > 0 {
+			s108 := make([]string, len(e105))
+			for i106, vs107 := range e105 {
+				if len(vs107) > 0 {
+					s108[i106] = vs107[0]
+				}
+			}
+			x.Notes = s108
+		}
+	}
+	k109 := qsgenKey(key, "price")
+	if s110, ok := qsgenValue(values, k109); ok {
+		n111, err := strconv.ParseFloat(s110, 64)
+		if err != nil {
+			return fmt.Errorf("invalid float value of %s: %q", k109, s110)
+		}
+		x.Price = n111
+	}
+	k112 := qsgenKey(key, "lat")
+	if s113, ok := qsgenValue(values, k112); ok {
+		n114, err := strconv.ParseFloat(s113, 32)
+		if err != nil {
+			return fmt.Errorf("invalid float value of %s: %q", k112, s113)
+		}
+		x.Lat = float32(n114)
+	}
+	k115 := qsgenKey(key, "rates")
+	{
+		e116, err := qsgenElements(values, k115, ",", -1)
+		if err != nil {
+			return fmt.Errorf("%s: %v", k115, err)
+		}
+		if len(e116) > 0 {
+			s119 := make([]float64, len(e116))
+			for i117, vs118 := range e116 {
+				if len(vs118) > 0 {
+					n120, err := strconv.ParseFloat(vs118[0], 64)
+					if err != nil {
+						return fmt.Errorf("invalid float value of %s: %q", k115+"["+strconv.Itoa(i117)+"]", vs118[0])
+					}
+					s119[i117] = n120
+				}
+			}
+			x.Rates = s119
+		}
+	}
+	k121 := qsgenKey(key, "total")
+	if s122, ok := qsgenValue(values, k121); ok {
+		n123, err := strconv.ParseFloat(s122, 64)
+		if err != nil {
+			return fmt.Errorf("invalid float value of %s: %q", k121, s122)
+		}
+		x.Total = n123
+	}
+	k124 := qsgenKey(key, "legacy")
+	if s125, ok := qsgenValue(values, k124); ok {
+		n126, err := qsgenParseBool(s125, "1", "0")
+		if err != nil {
+			return fmt.Errorf("invalid bool value of %s: %q", k124, s125)
+		}
+		x.Legacy = n126
+	}
+	k127 := qsgenKey(key, "flags")
+	if c128 := qsgenChildren(values, k127); len(c128) > 0 {
+		if x.Flags == nil {
+			x.Flags = make(map[string]bool, len(c128))
+		}
+		for _, seg129 := range c128 {
+			var mv131 bool
+			vs130 := values[k127+"["+seg129+"]"]
+			if len(vs130) > 0 {
+				n133, err := qsgenParseBool(vs130[0], "yes", "no")
+				if err != nil {
+					return fmt.Errorf("invalid bool value of %s: %q", k127+"["+seg129+"]", vs130[0])
+				}
+				mv131 = n133
+			}
+			x.Flags[seg129] = mv131
+		}
+	}
+	k134 := qsgenKey(key, "sig")
+	if s135, ok := qsgenValue(values, k134); ok {
+		b136, err := qsgenDecodeBytes(s135, "base64url")
+		if err != nil {
+			return fmt.Errorf("invalid bytes value of %s: %v", k134, err)
+		}
+		x.Sig = b136
+	}
+	k137 := qsgenKey(key, "hash")
+	if s138, ok := qsgenValue(values, k137); ok {
+		b139, err := qsgenDecodeBytes(s138, "hex")
+		if err != nil {
+			return fmt.Errorf("invalid bytes value of %s: %v", k137, err)
+		}
+		if len(b139) > 0 {
+			if len(b139) != 4 {
+				return fmt.Errorf("%s: decoded %d bytes for array of length 4", k137, len(b139))
+			}
+			copy(x.Hash[:], b139)
+		}
+	}
+	k140 := qsgenKey(key, "keys")
+	{
+		e141, err := qsgenElements(values, k140, "", -1)
+		if err != nil {
+			return fmt.Errorf("%s: %v", k140, err)
+		}
+		if len(e141) > 0 {
+			s144 := make([][]byte, len(e141))
+			for i142, vs143 := range e141 {
+				if len(vs143) > 0 {
+					b145, err := qsgenDecodeBytes(vs143[0], "base64raw")
+					if err != nil {
+						return fmt.Errorf("invalid bytes value of %s: %v", k140+"["+strconv.Itoa(i142)+"]", err)
+					}
+					s144[i142] = b145
+				}
+			}
+			x.Keys = s144
+		}
+	}
+	k146 := qsgenKey(key, "raw")
+	if s147, ok := qsgenValue(values, k146); ok {
+		b148, err := qsgenDecodeBytes(s147, "raw")
+		if err != nil {
+			return fmt.Errorf("invalid bytes value of %s: %v", k146, err)
+		}
+		x.Raw = b148
+	}
+	k149 := qsgenKey(key, "timeout")
+	if s150, ok := qsgenValue(values, k149); ok {
+		p151, err := qsgenParseDuration(s150, "ms")
+		if err != nil {
+			return fmt.Errorf("invalid value of %s: %v", k149, err)
+		}
+		x.Timeout = p151
+	}
+	k152 := qsgenKey(key, "ttl")
+	if s153, ok := qsgenValue(values, k152); ok {
+		p154, err := qsgenParseDuration(s153, "seconds")
+		if err != nil {
+			return fmt.Errorf("invalid value of %s: %v", k152, err)
+		}
+		x.TTL = &p154
+	}
+	k155 := qsgenKey(key, "retries")
+	{
+		e156, err := qsgenElements(values, k155, ",", -1)
+		if err != nil {
+			return fmt.Errorf("%s: %v", k155, err)
+		}
+		if len(e156) > 0 {
+			s159 := make([]time.Duration, len(e156))
+			for i157, vs158 := range e156 {
+				if len(vs158) > 0 {
+					p160, err := qsgenParseDuration(vs158[0], "")
+					if err != nil {
+						return fmt.Errorf("invalid value of %s: %v", k155+"["+strconv.Itoa(i157)+"]", err)
+					}
+					s159[i157] = p160
+				}
+			}
+			x.Retries = s159
+		}
+	}
+	k161 := qsgenKey(key, "home")
+	if s162, ok := qsgenValue(values, k161); ok {
+		p163, err := url.Parse(s162)
+		if err != nil {
+			return fmt.Errorf("invalid value of %s: %v", k161, err)
+		}
+		x.Home = *p163
+	}
+	k164 := qsgenKey(key, "proxy")
+	if s165, ok := qsgenValue(values, k164); ok {
+		p166, err := url.Parse(s165)
+		if err != nil {
+			return fmt.Errorf("invalid value of %s: %v", k164, err)
+		}
+		x.Proxy = p166
+	}
+	k167 := qsgenKey(key, "addr")
+	if s168, ok := qsgenValue(values, k167); ok {
+		if err := x.Addr.UnmarshalText([]byte(s168)); err != nil {
+			return fmt.Errorf("invalid value of %s: %v", k167, err)
+		}
+	}
+	k169 := qsgenKey(key, "subnet")
+	if s170, ok := qsgenValue(values, k169); ok {
+		p171, err := qsgenParseIPNet(s170)
+		if err != nil {
+			return fmt.Errorf("invalid value of %s: %v", k169, err)
+		}
+		x.Subnet = p171
+	}
+	k172 := qsgenKey(key, "zone")
+	if s173, ok := qsgenValue(values, k172); ok {
+		p174, err := time.LoadLocation(s173)
+		if err != nil {
+			return fmt.Errorf("invalid value of %s: %v", k172, err)
+		}
+		x.Zone = p174
+	}
+	k175 := qsgenKey(key, "match")
+	if s176, ok := qsgenValue(values, k175); ok {
+		p177, err := regexp.Compile(s176)
+		if err != nil {
+			return fmt.Errorf("invalid value of %s: %v", k175, err)
+		}
+		x.Match = p177
+	}
+	k178 := qsgenKey(key, "amount")
+	if s179, ok := qsgenValue(values, k178); ok {
+		p180 := new(big.Int)
+		err := p180.UnmarshalText([]byte(s179))
+		if err != nil {
+			return fmt.Errorf("invalid value of %s: %v", k178, err)
+		}
+		x.Amount = *p180
+	}
+	k181 := qsgenKey(key, "share")
+	if s182, ok := qsgenValue(values, k181); ok {
+		p183 := new(big.Rat)
+		err := p183.UnmarshalText([]byte(s182))
+		if err != nil {
+			return fmt.Errorf("invalid value of %s: %v", k181, err)
+		}
+		x.Share = p183
+	}
+	if s185, ok := qsgenValue(values, qsgenKey(key, "nick")); ok {
+		x.Nick.String = s185
+		x.Nick.Valid = true
+	}
+	k186 := qsgenKey(key, "age")
+	if qsgenHas(values, k186) {
+		if x.Age == nil {
+			x.Age = new(sql.NullInt64)
+		}
+		if s187, ok := qsgenValue(values, k186); ok {
+			n188, err := strconv.ParseInt(s187, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid int value of %s: %q", k186, s187)
+			}
+			(*x.Age).Int64 = n188
+			(*x.Age).Valid = true
+		}
+	}
+	k189 := qsgenKey(key, "seen")
+	if s190, ok := qsgenValue(values, k189); ok {
+		t191, err := qsgenParseTime(s190, "unix")
+		if err != nil {
+			return fmt.Errorf("invalid time value of %s: %v", k189, err)
+		}
+		x.Seen.Time = t191
+		x.Seen.Valid = true
+	}
+	k192 := qsgenKey(key, "scores")
+	{
+		e193, err := qsgenElements(values, k192, ",", -1)
+		if err != nil {
+			return fmt.Errorf("%s: %v", k192, err)
+		}
+		if len(e193) > 0 {
+			s196 := make([]sql.NullFloat64, len(e193))
+			for i194, vs195 := range e193 {
+				if len(vs195) > 0 {
+					n197, err := strconv.ParseFloat(vs195[0], 64)
+					if err != nil {
+						return fmt.Errorf("invalid float value of %s: %q", k192+"["+strconv.Itoa(i194)+"]", vs195[0])
+					}
+					s196[i194].Float64 = n197
+					s196[i194].Valid = true
+				}
+			}
+			x.Scores = s196
+		}
+	}
+	return nil
+}
+
+// EncodeValues 实现query.Encoder，输出的参数与query.Values一致
+func (x Node) EncodeValues(key string, v *url.Values) error {
+	return x.EncodeValuesLevel(key, v, 1)
+}
+
+// EncodeValuesLevel 与EncodeValues相同，level为x所在的嵌套层数，超过query包默认的最大层数时返回错误
+func (x Node) EncodeValuesLevel(key string, v *url.Values, level int) error {
+	k1 := qsgenKey(key, "name")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k1, "Name", x.Name)
+	}
+	v.Add(k1, x.Name)
+	k2 := qsgenKey(key, "weight")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k2, "Weight", x.Weight)
+	}
+	if x.Weight != nil {
+		v.Add(k2, strconv.FormatInt(int64((*x.Weight)), 10))
+	}
+	k3 := qsgenKey(key, "children")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k3, "Children", x.Children)
+	}
+	for i4 := range x.Children {
+		if level+2 > qsgenMaxLevel {
+			return qsgenTooDeep(k3+"["+strconv.Itoa(i4)+"]", "Children["+strconv.Itoa(i4)+"]", x.Children[i4])
+		}
+		if err := x.Children[i4].EncodeValuesLevel(k3+"["+strconv.Itoa(i4)+"]", v, level+2); err != nil {
+			return qsgenError(k3+"["+strconv.Itoa(i4)+"]", "Children["+strconv.Itoa(i4)+"]", x.Children[i4], err)
+		}
+	}
+	k5 := qsgenKey(key, "parent")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k5, "Parent", x.Parent)
+	}
+	if x.Parent != nil {
+		if err := (*x.Parent).EncodeValuesLevel(k5, v, level+1); err != nil {
+			return qsgenError(k5, "Parent", (*x.Parent), err)
+		}
+	}
+	k6 := qsgenKey(key, "days")
+	if level+1 > qsgenMaxLevel {
+		return qsgenTooDeep(k6, "Days", x.Days)
+	}
+	if len(x.Days) > 0 {
+		parts8 := make([]string, 0, len(x.Days))
+		for i7 := range x.Days {
+			if level+2 > qsgenMaxLevel {
+				return qsgenTooDeep(k6, "Days["+strconv.Itoa(i7)+"]", x.Days[i7])
+			}
+			parts8 = append(parts8, strconv.FormatUint(uint64(x.Days[i7]), 10))
+		}
+		if len(parts8) > 0 {
+			v.Add(k6, strings.Join(parts8, "|"))
+		}
+	}
+	return nil
+}
+
+// DecodeValues 实现query.Decoder，解码规则与query.Unmarshal一致
+func (x *Node) DecodeValues(key string, values url.Values) error {
+	if s2, ok := qsgenValue(values, qsgenKey(key, "name")); ok {
+		x.Name = s2
+	}
+	k3 := qsgenKey(key, "weight")
+	if qsgenHas(values, k3) {
+		if x.Weight == nil {
+			x.Weight = new(int)
+		}
+		if s4, ok := qsgenValue(values, k3); ok {
+			n5, err := strconv.ParseInt(s4, 10, strconv.IntSize)
+			if err != nil {
+				return fmt.Errorf("invalid int value of %s: %q", k3, s4)
+			}
+			(*x.Weight) = int(n5)
+		}
+	}
+	k6 := qsgenKey(key, "children")
+	{
+		e7, err := qsgenElements(values, k6, "", -1)
+		if err != nil {
+			return fmt.Errorf("%s: %v", k6, err)
+		}
+		if len(e7) > 0 {
+			s10 := make([]Node, len(e7))
+			for i8 := range e7 {
+				if err := s10[i8].DecodeValues(k6+"["+strconv.Itoa(i8)+"]", values); err != nil {
+					return err
+				}
+			}
+			x.Children = s10
+		}
+	}
+	k11 := qsgenKey(key, "parent")
+	if qsgenHas(values, k11) {
+		if x.Parent == nil {
+			x.Parent = new(Node)
+		}
+		if err := (*x.Parent).DecodeValues(k11, values); err != nil {
+			return err
+		}
+	}
+	k12 := qsgenKey(key, "days")
+	{
+		e13, err := qsgenElements(values, k12, "|", -1)
+		if err != nil {
+			return fmt.Errorf("%s: %v", k12, err)
+		}
+		if len(e13) > 0 {
+			s16 := make([]uint8, len(e13))
+			for i14, vs15 := range e13 {
+				if len(vs15) > 0 {
+					n17, err := strconv.ParseUint(vs15[0], 10, 8)
+					if err != nil {
+						return fmt.Errorf("invalid uint value of %s: %q", k12+"["+strconv.Itoa(i14)+"]", vs15[0])
+					}
+					s16[i14] = uint8(n17)
+				}
+			}
+			x.Days = s16
+		}
+	}
+	return nil
+}
+
+// qsgenKey 拼接参数名，与query包默认的中括号格式一致
+func qsgenKey(key, name string) string {
+	if key == "" {
+		return name
+	}
+	return key + "[" + name + "]"
+}
+
+// qsgenError 将编码错误包装为*query.EncodeError
+func qsgenError(key, field string, v interface{}, err error) error {
+	var ee *query.EncodeError
+	if errors.As(err, &ee) {
+		return err
+	}
+	return &query.EncodeError{Key: key, Field: field, Type: reflect.TypeOf(v), Err: err}
+}
+
+// qsgenMaxLevel 与query包默认的最大嵌套层数一致
+const qsgenMaxLevel = 7
+
+// qsgenTooDeep 超过最大嵌套层数时返回的错误，与query包一致
+func qsgenTooDeep(key, field string, v interface{}) error {
+	return qsgenError(key, field, v, fmt.Errorf("%w, the max is: %v", query.ErrTooDeep, qsgenMaxLevel))
+}
+
+// qsgenDecimal 浮点数不包含小数点时补充 .0，与query包的decimal选项一致
+func qsgenDecimal(s string) string {
+	if strings.ContainsAny(s, ".IN") {
+		return s
+	}
+	if i := strings.IndexAny(s, "eEpP"); i >= 0 {
+		return s[:i] + ".0" + s[i:]
+	}
+	return s + ".0"
+}
+
+// qsgenBool 按标签中的int、bool=选项输出布尔值
+func qsgenBool(b bool, t, f string) string {
+	if b {
+		return t
+	}
+	return f
+}
+
+// qsgenParseBool 先匹配标签中指定的值，再按strconv.ParseBool解析
+func qsgenParseBool(s, t, f string) (bool, error) {
+	switch s {
+	case t:
+		return true, nil
+	case f:
+		return false, nil
+	}
+	return strconv.ParseBool(s)
+}
+
+// qsgenEncodeBytes 按标签中的格式编码字节，与query包的BytesFormat一致
+func qsgenEncodeBytes(b []byte, format string) string {
+	switch format {
+	case "base64":
+		return base64.StdEncoding.EncodeToString(b)
+	case "base64url":
+		return base64.URLEncoding.EncodeToString(b)
+	case "base64raw":
+		return base64.RawStdEncoding.EncodeToString(b)
+	case "base64urlraw":
+		return base64.RawURLEncoding.EncodeToString(b)
+	case "hex":
+		return hex.EncodeToString(b)
+	}
+	return string(b)
+}
+
+// qsgenDecodeBytes 按标签中的格式解码字节，与query包的BytesFormat一致
+func qsgenDecodeBytes(s, format string) ([]byte, error) {
+	switch format {
+	case "base64":
+		return base64.StdEncoding.DecodeString(s)
+	case "base64url":
+		return base64.URLEncoding.DecodeString(s)
+	case "base64raw":
+		return base64.RawStdEncoding.DecodeString(s)
+	case "base64urlraw":
+		return base64.RawURLEncoding.DecodeString(s)
+	case "hex":
+		return hex.DecodeString(s)
+	}
+	return []byte(s), nil
+}
+
+// qsgenValue 返回参数的第一个值
+func qsgenValue(values url.Values, key string) (string, bool) {
+	vs := values[key]
+	if len(vs) == 0 {
+		return "", false
+	}
+	return vs[0], true
+}
+
+// qsgenHas 是否存在key或以key为前缀的参数
+func qsgenHas(values url.Values, key string) bool {
+	if _, ok := values[key]; ok {
+		return true
+	}
+	for k := range values {
+		if _, ok := qsgenChild(k, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// qsgenChildren 返回key下一层的参数名并排序，如 key[a]、key[b][c] 返回 a、b
+func qsgenChildren(values url.Values, key string) []string {
+	var segs []string
+	seen := make(map[string]bool)
+	for k := range values {
+		if seg, ok := qsgenChild(k, key); ok && !seen[seg] {
+			seen[seg] = true
+			segs = append(segs, seg)
+		}
+	}
+	sort.Strings(segs)
+	return segs
+}
+
+// qsgenChild 返回参数名k在key下一层的名称，k的剩余部分需为 [a][b] 的格式
+func qsgenChild(k, key string) (string, bool) {
+	if !strings.HasPrefix(k, key+"[") {
+		return "", false
+	}
+	rest := k[len(key):]
+	seg := ""
+	for first := true; len(rest) > 0; first = false {
+		j := strings.IndexByte(rest, ']')
+		if rest[0] != '[' || j < 0 {
+			return "", false
+		}
+		if first {
+			seg = rest[1:j]
+		}
+		rest = rest[j+1:]
+	}
+	return seg, true
+}
+
+// qsgenElements 返回key对应的数组元素，规则与query包一致：元素按下标放置，缺失的下标为nil，
+// 没有下标的重复参数及空中括号的参数按出现顺序作为元素，指定分隔符时对每个值进行拆分，
+// length为数组的长度，切片为-1，切片缺失的下标最多为query包的默认值100
+func qsgenElements(values url.Values, key, delimiter string, length int) ([][]string, error) {
+	segs := qsgenChildren(values, key)
+	var elems [][]string
+	switch {
+	case len(segs) == 0:
+		elems = qsgenSplit(values[key], delimiter)
+	case len(segs) == 1 && segs[0] == "" && len(qsgenChildren(values, key+"[]")) == 0:
+		elems = qsgenSplit(values[key+"[]"], delimiter)
+	default:
+		indexes := make([]int, len(segs))
+		max := -1
+		for i, seg := range segs {
+			n, err := strconv.Atoi(seg)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid index: %q", seg)
+			}
+			indexes[i] = n
+			if n > max {
+				max = n
+			}
+		}
+		if length < 0 && max+1-len(segs) > 100 {
+			return nil, fmt.Errorf("index %d leaves %d missing elements, the max is: 100", max, max+1-len(segs))
+		}
+		if length >= 0 && max >= length {
+			return nil, fmt.Errorf("index %d out of range for array of length %d", max, length)
+		}
+		elems = make([][]string, max+1)
+		for i, seg := range segs {
+			elems[indexes[i]] = values[key+"["+seg+"]"]
+		}
+		return elems, nil
+	}
+	if length >= 0 && len(elems) > length {
+		return nil, fmt.Errorf("index %d out of range for array of length %d", len(elems)-1, length)
+	}
+	return elems, nil
+}
+
+// qsgenSplit 每个值作为一个元素，指定分隔符时对值进行拆分
+func qsgenSplit(vs []string, delimiter string) [][]string {
+	var elems [][]string
+	for _, s := range vs {
+		if delimiter == "" {
+			elems = append(elems, []string{s})
+			continue
+		}
+		for _, part := range strings.Split(s, delimiter) {
+			elems = append(elems, []string{part})
+		}
+	}
+	return elems
+}
+
+// qsgenFormatDuration 按单位输出时长，与query包的seconds、ms选项一致
+func qsgenFormatDuration(d time.Duration, unit string) string {
+	switch unit {
+	case "seconds":
+		return strconv.FormatFloat(float64(d)/float64(time.Second), 'f', -1, 64)
+	case "ms":
+		return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', -1, 64)
+	}
+	return d.String()
+}
+
+// qsgenParseDuration 按单位解析时长，未指定单位时也接受以纳秒为单位的整数
+func qsgenParseDuration(s, unit string) (time.Duration, error) {
+	if unit == "seconds" || unit == "ms" {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, err
+		}
+		if unit == "seconds" {
+			return time.Duration(math.Round(f * float64(time.Second))), nil
+		}
+		return time.Duration(math.Round(f * float64(time.Millisecond))), nil
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Duration(n), nil
+	}
+	return time.ParseDuration(s)
+}
+
+// qsgenParseIPNet 解析CIDR格式的网络并保留主机地址，与query包一致
+func qsgenParseIPNet(s string) (*net.IPNet, error) {
+	ip, n, err := net.ParseCIDR(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(n.IP) == net.IPv4len {
+		ip = ip.To4()
+	}
+	return &net.IPNet{IP: ip, Mask: n.Mask}, nil
+}
+
+// qsgenParseTime 按格式解析时间，时间戳解析为UTC时间
+func qsgenParseTime(s, layout string) (time.Time, error) {
+	switch layout {
+	case "unix", "unixmilli":
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if layout == "unix" {
+			return time.Unix(n, 0).UTC(), nil
+		}
+		return time.Unix(n/1e3, n%1e3*1e6).UTC(), nil
+	}
+	return time.Parse(layout, s)
+}
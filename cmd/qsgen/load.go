@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"strings"
+)
+
+// annotation 标记需要生成代码的类型
+const annotation = "//qs:generate"
+
+// pkgInfo 解析后的包
+type pkgInfo struct {
+	types *types.Package
+	files []*ast.File
+}
+
+// loadPackage 解析并检查dir目录中的包，忽略之前生成的文件
+func loadPackage(dir, output string) (*pkgInfo, error) {
+	bp, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, name := range bp.GoFiles {
+		if name == filepath.Base(output) {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	conf := types.Config{
+		Importer: fallbackImporter{importer.Default(), importer.ForCompiler(fset, "source", nil)},
+		// 忽略引用了生成代码等错误，无法解析的字段在生成时报错
+		Error: func(error) {},
+	}
+	pkg, _ := conf.Check(bp.ImportPath, fset, files, nil)
+	return &pkgInfo{types: pkg, files: files}, nil
+}
+
+// fallbackImporter 优先使用编译后的导出数据，失败时从源码解析
+type fallbackImporter struct {
+	primary, fallback types.Importer
+}
+
+func (i fallbackImporter) Import(path string) (*types.Package, error) {
+	pkg, err := i.primary.Import(path)
+	if err != nil {
+		return i.fallback.Import(path)
+	}
+	return pkg, nil
+}
+
+// targets 返回需要生成代码的类型，包括带有注释标记的类型及names指定的类型，按声明顺序排列
+func (p *pkgInfo) targets(names []string) ([]*types.TypeName, error) {
+	wanted := make(map[string]bool)
+	for _, name := range names {
+		wanted[strings.TrimSpace(name)] = true
+	}
+	var objs []*types.TypeName
+	for _, f := range p.files {
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				ts := spec.(*ast.TypeSpec)
+				annotated := hasAnnotation(ts.Doc) || (len(gd.Specs) == 1 && hasAnnotation(gd.Doc))
+				if !annotated && !wanted[ts.Name.Name] {
+					continue
+				}
+				delete(wanted, ts.Name.Name)
+				obj, ok := p.types.Scope().Lookup(ts.Name.Name).(*types.TypeName)
+				if !ok {
+					return nil, fmt.Errorf("%s is not a type", ts.Name.Name)
+				}
+				if _, ok := obj.Type().Underlying().(*types.Struct); !ok {
+					return nil, fmt.Errorf("%s is not a struct type", ts.Name.Name)
+				}
+				objs = append(objs, obj)
+			}
+		}
+	}
+	for name := range wanted {
+		return nil, fmt.Errorf("type %s not found", name)
+	}
+	if len(objs) == 0 {
+		return nil, fmt.Errorf("no types to generate, annotate types with %s or use -type", annotation)
+	}
+	return objs, nil
+}
+
+// hasAnnotation 注释中是否包含标记
+func hasAnnotation(doc *ast.CommentGroup) bool {
+	if doc == nil {
+		return false
+	}
+	for _, c := range doc.List {
+		if strings.TrimSpace(c.Text) == annotation {
+			return true
+		}
+	}
+	return false
+}
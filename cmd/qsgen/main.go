@@ -0,0 +1,116 @@
+// qsgen 为带有qs标签的结构体生成不依赖反射的EncodeValues、DecodeValues方法，
+// 生成的方法输出的参数与query.Values使用默认配置时一致，解码与query.Unmarshal一致。
+//
+// 在类型声明前添加 //qs:generate 注释，或通过-type参数指定类型，
+// 然后在包中添加go:generate指令：
+//
+//	//go:generate go run github.com/rumis/querystring/cmd/qsgen -decode -test
+//
+//	//qs:generate
+//	type Options struct {
+//		Query string `qs:"q"`
+//		Page  int    `qs:"page,omitempty"`
+//	}
+//
+// 参数：
+//
+//	-type    逗号分隔的类型名，与 //qs:generate 注释的类型合并
+//	-output  生成的文件名，默认为qs_gen.go
+//	-tag     读取的标签名，默认为qs
+//	-decode  同时生成DecodeValues方法
+//	-test    同时生成测试文件，使用随机值验证生成的方法与反射编解码的结果一致
+//
+// 生成的代码使用默认配置：中括号格式的参数名、默认的时间格式及数组格式，
+// 不受SetTimeFormat及NewEncoder选项的影响。不支持interface{}、chan、func类型的字段，
+// 解码时数组、map的元素只支持基础类型、时间、encoding.TextUnmarshaler及Decoder。
+// 引用自身的类型需要同时生成。
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// config 生成选项
+type config struct {
+	types  []string
+	output string
+	tagKey string
+	decode bool
+	test   bool
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("qsgen: ")
+
+	var cfg config
+	typeNames := flag.String("type", "", "comma-separated list of type names")
+	flag.StringVar(&cfg.output, "output", "qs_gen.go", "output file name")
+	flag.StringVar(&cfg.tagKey, "tag", "qs", "struct tag key")
+	flag.BoolVar(&cfg.decode, "decode", false, "generate DecodeValues methods")
+	flag.BoolVar(&cfg.test, "test", false, "generate tests comparing the generated methods with the reflective encoder")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: qsgen [flags] [directory]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *typeNames != "" {
+		cfg.types = strings.Split(*typeNames, ",")
+	}
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+	if err := run(dir, cfg); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run 生成代码并写入dir目录
+func run(dir string, cfg config) error {
+	code, test, err := generate(dir, cfg)
+	if err != nil {
+		return err
+	}
+	output := filepath.Join(dir, cfg.output)
+	if err := os.WriteFile(output, code, 0644); err != nil {
+		return err
+	}
+	if test != nil {
+		return os.WriteFile(testFileName(output), test, 0644)
+	}
+	return nil
+}
+
+// testFileName 测试文件名，如 qs_gen.go 对应 qs_gen_test.go
+func testFileName(output string) string {
+	return strings.TrimSuffix(output, ".go") + "_test.go"
+}
+
+// generate 解析dir目录中的包并生成代码，未开启-test时test为nil
+func generate(dir string, cfg config) (code, test []byte, err error) {
+	pkg, err := loadPackage(dir, cfg.output)
+	if err != nil {
+		return nil, nil, err
+	}
+	objs, err := pkg.targets(cfg.types)
+	if err != nil {
+		return nil, nil, err
+	}
+	g := newGenerator(pkg.types, objs, cfg)
+	if code, err = g.generate(); err != nil {
+		return nil, nil, err
+	}
+	if cfg.test {
+		if test, err = g.generateTest(); err != nil {
+			return nil, nil, err
+		}
+	}
+	return code, test, nil
+}
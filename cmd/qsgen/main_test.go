@@ -0,0 +1,203 @@
+package main
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rumis/querystring/cmd/qsgen/internal/example"
+	"github.com/rumis/querystring/query"
+)
+
+func TestGenerate_Example(t *testing.T) {
+	dir := filepath.Join("internal", "example")
+	cfg := config{output: "qs_gen.go", tagKey: "qs", decode: true, test: true}
+	code, test, err := generate(dir, cfg)
+	if err != nil {
+		t.Fatalf("generate returned error: %v", err)
+	}
+
+	// 提交的生成文件需与当前生成器的输出一致，否则需要重新运行go generate
+	for name, got := range map[string][]byte{"qs_gen.go": code, "qs_gen_test.go": test} {
+		want, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(want) {
+			t.Errorf("%s is out of date, run go generate in %s", name, dir)
+		}
+	}
+}
+
+func TestGenerate_Cycle(t *testing.T) {
+	n := &example.Node{Name: "a"}
+	n.Parent = n
+
+	// 与反射编码一致，超过默认的最大层数时返回错误而不是无限递归
+	v := url.Values{}
+	err := n.EncodeValues("", &v)
+	var ee *query.EncodeError
+	if !errors.Is(err, query.ErrTooDeep) || !errors.As(err, &ee) {
+		t.Fatalf("EncodeValues(cycle) returned error %v, want EncodeError wrapping ErrTooDeep", err)
+	}
+	if want := "parent[parent][parent][parent][parent][parent][name]"; ee.Key != want {
+		t.Errorf("EncodeValues(cycle) error key = %q, want %q", ee.Key, want)
+	}
+	if _, err := query.Values(struct{ N *example.Node }{n}); !errors.Is(err, query.ErrTooDeep) {
+		t.Errorf("Values(cycle) returned error %v, want ErrTooDeep", err)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		src     string
+		cfg     config
+		want    []string
+		wantErr string
+	}{
+		{
+			src: `
+//qs:generate
+type T struct {
+	A string ` + "`qs:\"a,omitempty\"`" + `
+	B []int  ` + "`qs:\"b,repeat\"`" + `
+}`,
+			want: []string{
+				"func (x T) EncodeValues(key string, v *url.Values) error {",
+				"return x.EncodeValuesLevel(key, v, 1)",
+				`v.Add(k1, x.A)`,
+				"if level+2 > qsgenMaxLevel {",
+				`v.Add(k2, strconv.FormatInt(int64(x.B[i3]), 10))`,
+			},
+		},
+		{
+			src: `
+type T struct {
+	A string
+}`,
+			cfg: config{types: []string{"T"}, decode: true},
+			want: []string{
+				"func (x T) EncodeValues(key string, v *url.Values) error {",
+				"func (x *T) DecodeValues(key string, values url.Values) error {",
+			},
+		},
+		{
+			src: `
+//qs:generate
+type T struct {
+	V interface{}
+}`,
+			wantErr: "V: unsupported type interface{} for encoding",
+		},
+		{
+			src: `
+//qs:generate
+type T struct {
+	N *N
+}
+
+type N struct {
+	Next *N
+}`,
+			wantErr: "recursive type N must be generated too",
+		},
+		{
+			src: `
+//qs:generate
+type T struct {
+	M map[float64]int
+}`,
+			wantErr: "unsupported type float64 for map keys",
+		},
+		{
+			src: `
+//qs:generate
+type T struct {
+	S []struct{ A int }
+}`,
+			cfg:     config{decode: true},
+			wantErr: "unsupported type struct{A int} for decoding elements",
+		},
+		{
+			src: `
+//qs:generate
+type T struct {
+	Page int ` + "`qs:\"page,min=1\"`" + `
+}`,
+			cfg:     config{decode: true},
+			wantErr: `Page: option "min=1" is not supported with -decode`,
+		},
+		{
+			src: `
+//qs:generate
+type T struct {
+	F struct{ S []int } ` + "`qs:\"f,omitzero\"`" + `
+}`,
+			wantErr: "F: omitzero is not supported for non-comparable type struct{S []int}",
+		},
+		{
+			src: `
+//qs:generate
+type T struct {
+	F []string ` + "`qs:\"f,omitempty=deep\"`" + `
+}`,
+			wantErr: `F: option "omitempty=deep" is not supported`,
+		},
+		{
+			src: `
+import "database/sql/driver"
+
+//qs:generate
+type T struct {
+	C Cents
+}
+
+type Cents int
+
+func (c Cents) Value() (driver.Value, error) { return int64(c), nil }`,
+			wantErr: "C: unsupported type Cents for driver.Valuer",
+		},
+		{
+			src:     `type T struct{}`,
+			cfg:     config{types: []string{"U"}},
+			wantErr: "type U not found",
+		},
+		{
+			src: `
+//qs:generate
+type T int`,
+			wantErr: "T is not a struct type",
+		},
+	}
+
+	for _, tt := range tests {
+		dir := t.TempDir()
+		src := "package p\n" + tt.src + "\n"
+		if err := os.WriteFile(filepath.Join(dir, "p.go"), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+		cfg := tt.cfg
+		cfg.output = "qs_gen.go"
+		cfg.tagKey = "qs"
+
+		code, _, err := generate(dir, cfg)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("generate(%s) returned error %v, want %q", tt.src, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("generate(%s) returned error: %v", tt.src, err)
+			continue
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(string(code), want) {
+				t.Errorf("generate(%s) did not contain %q:\n%s", tt.src, want, code)
+			}
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// testIterations 每个类型使用随机值验证的次数
+const testIterations = 200
+
+// generateTest 生成测试，使用随机值比较生成的方法与反射编解码的结果
+func (g *generator) generateTest() ([]byte, error) {
+	var b bytes.Buffer
+	for _, obj := range g.objs {
+		name := obj.Name()
+		fmt.Fprintf(&b, "func TestQSGen_%s(t *testing.T) {\n", name)
+		fmt.Fprintf(&b, "// plain 与%s的字段相同但没有生成的方法，使用反射编解码\n", name)
+		fmt.Fprintf(&b, "type plain %s\n", name)
+		fmt.Fprintf(&b, "r := rand.New(rand.NewSource(1))\n")
+		fmt.Fprintf(&b, "for i := 0; i < %d; i++ {\n", testIterations)
+		fmt.Fprintf(&b, "var x %s\n", name)
+		b.WriteString(`qsgenFill(reflect.ValueOf(&x).Elem(), r, 0)
+p := plain(x)
+want, wantErr := query.Values(&p)
+got := url.Values{}
+gotErr := x.EncodeValues("", &got)
+if (gotErr != nil) != (wantErr != nil) {
+	t.Fatalf("EncodeValues(%+v) returned error %v, want %v", x, gotErr, wantErr)
+}
+if wantErr != nil {
+	continue
+}
+if !reflect.DeepEqual(got, want) {
+	t.Fatalf("EncodeValues(%+v) = %v, want %v", x, got, want)
+}
+`)
+		if g.cfg.decode {
+			fmt.Fprintf(&b, "var dx %s\n", name)
+			b.WriteString(`var dp plain
+gotErr = dx.DecodeValues("", want)
+wantErr = query.Unmarshal(want, &dp)
+if (gotErr != nil) != (wantErr != nil) {
+	t.Fatalf("DecodeValues(%v) returned error %v, want %v", want, gotErr, wantErr)
+}
+if !reflect.DeepEqual(plain(dx), dp) {
+	t.Fatalf("DecodeValues(%v) = %+v, want %+v", want, dx, dp)
+}
+`)
+		}
+		b.WriteString("}\n}\n\n")
+	}
+	b.WriteString(fillSource)
+	return g.file(b.String(), nil)
+}
+
+// fillSource 使用随机值填充导出字段的函数
+const fillSource = `
+// qsgenFill 使用随机值填充v中可以赋值的字段，map的string类型key只包含字母，
+// 约四分之一的值保持为零值，如空字符串、nil切片、nil map及零值时间
+func qsgenFill(v reflect.Value, r *rand.Rand, depth int) {
+	if depth > 10 || r.Intn(4) == 0 {
+		return
+	}
+	if v.Type() == reflect.TypeOf(time.Time{}) {
+		v.Set(reflect.ValueOf(time.Unix(r.Int63n(1<<32), 0).UTC()))
+		return
+	}
+	if v.Type() == reflect.TypeOf(net.IP{}) {
+		v.Set(reflect.ValueOf(net.IPv4(byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)))))
+		return
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		p := reflect.New(v.Type().Elem())
+		qsgenFill(p.Elem(), r, depth+1)
+		v.Set(p)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if f := v.Field(i); f.CanSet() {
+				qsgenFill(f, r, depth+1)
+			}
+		}
+	case reflect.Slice:
+		n := r.Intn(4)
+		s := reflect.MakeSlice(v.Type(), n, n)
+		for i := 0; i < n; i++ {
+			qsgenFill(s.Index(i), r, depth+1)
+		}
+		v.Set(s)
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			qsgenFill(v.Index(i), r, depth+1)
+		}
+	case reflect.Map:
+		n := r.Intn(4)
+		m := reflect.MakeMapWithSize(v.Type(), n)
+		for i := 0; i < n; i++ {
+			k := reflect.New(v.Type().Key()).Elem()
+			if k.Kind() == reflect.String {
+				k.SetString(qsgenWord(r, "abcdefghijklmnopqrstuvwxyz"))
+			} else {
+				qsgenFill(k, r, depth+1)
+			}
+			e := reflect.New(v.Type().Elem()).Elem()
+			qsgenFill(e, r, depth+1)
+			m.SetMapIndex(k, e)
+		}
+		v.Set(m)
+	case reflect.String:
+		v.SetString(qsgenWord(r, "abcxyz019 ,|;&=%+-_.[]"))
+	case reflect.Bool:
+		v.SetBool(r.Intn(2) == 0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(r.Int63n(200) - 100)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v.SetUint(uint64(r.Int63n(200)))
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(float64(r.Int63n(2000000)-1000000) / 1000)
+	}
+}
+
+// qsgenWord 返回由chars中的字符组成的随机字符串
+func qsgenWord(r *rand.Rand, chars string) string {
+	b := make([]byte, 1+r.Intn(6))
+	for i := range b {
+		b[i] = chars[r.Intn(len(chars))]
+	}
+	return string(b)
+}
+`
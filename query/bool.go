@@ -0,0 +1,68 @@
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// boolStyle 布尔值的输出形式，set为false时使用 true、false
+type boolStyle struct {
+	set bool
+	// 值为false且f为空时不输出该参数
+	t, f string
+}
+
+// parseBoolOption 解析标签中布尔值的格式选项：int、bool=yes|no，不是布尔值选项时返回false
+func (s *boolStyle) parseBoolOption(opt string) bool {
+	switch {
+	case opt == "int":
+		*s = boolStyle{true, "1", "0"}
+	case strings.HasPrefix(opt, "bool="):
+		parts := strings.SplitN(strings.TrimPrefix(opt, "bool="), "|", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return false
+		}
+		*s = boolStyle{true, parts[0], parts[1]}
+	default:
+		return false
+	}
+	return true
+}
+
+// boolStyleOf 返回当前域使用的布尔值格式，字段的选项优先于全局配置
+func (o *options) boolStyleOf(scope ScopeOptions) boolStyle {
+	if scope.format != nil && scope.format.bool.set {
+		return scope.format.bool
+	}
+	return o.boolStyle
+}
+
+// boolEncode 按格式输出布尔值，没有指定格式时与fmt.Sprint的输出一致
+func (e *encodeState) boolEncode(scope ScopeOptions, ti *typeInfo, val reflect.Value) {
+	style := e.opts.boolStyleOf(scope)
+	// 实现了fmt.Stringer等方法的类型由fmt输出
+	if !style.set || ti.stringer || ti.formatter {
+		e.values.add(scope.Scope, fmt.Sprint(val.Interface()))
+		return
+	}
+	if val.Bool() {
+		e.values.add(scope.Scope, style.t)
+	} else if style.f != "" {
+		e.values.add(scope.Scope, style.f)
+	}
+}
+
+// parseBool 解析布尔值，指定格式时先匹配格式中的值，再按strconv.ParseBool解析
+func (s boolStyle) parseBool(str string) (bool, error) {
+	if s.set {
+		switch str {
+		case s.t:
+			return true, nil
+		case s.f:
+			return false, nil
+		}
+	}
+	return strconv.ParseBool(str)
+}
@@ -0,0 +1,92 @@
+package query
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// onOff 实现了fmt.Stringer的布尔值
+type onOff bool
+
+func (b onOff) String() string {
+	if b {
+		return "ON"
+	}
+	return "OFF"
+}
+
+func TestValues_BoolFormat(t *testing.T) {
+	tests := []struct {
+		input interface{}
+		want  url.Values
+	}{
+		{
+			struct {
+				A bool  `qs:"a,int"`
+				B bool  `qs:"b,int"`
+				C bool  `qs:"c,bool=yes|no"`
+				D bool  `qs:"d,bool=on|"`
+				E bool  `qs:"e,bool=on|"`
+				F bool  `qs:"f"`
+				G onOff `qs:"g,int"`
+			}{A: true, C: false, D: true, G: true},
+			url.Values{"a": {"1"}, "b": {"0"}, "c": {"no"}, "d": {"on"}, "f": {"false"}, "g": {"ON"}},
+		},
+		{
+			struct {
+				L []bool          `qs:"l,comma,int"`
+				M map[string]bool `qs:"m,bool=y|n"`
+				P *bool           `qs:"p,int"`
+			}{[]bool{true, false}, map[string]bool{"x": true, "y": false}, new(bool)},
+			url.Values{"l": {"1,0"}, "m[x]": {"y"}, "m[y]": {"n"}, "p": {"0"}},
+		},
+	}
+
+	for _, tt := range tests {
+		testValue(t, tt.input, tt.want)
+	}
+}
+
+func TestEncoder_BoolFormat(t *testing.T) {
+	input := struct {
+		A bool
+		B bool
+		C bool `qs:"C,bool=yes|no"`
+	}{true, false, true}
+
+	testEncoderValue(t, NewEncoder(WithBoolFormat("1", "0")), input,
+		url.Values{"A": {"1"}, "B": {"0"}, "C": {"yes"}})
+	testEncoderValue(t, NewEncoder(WithBoolFormat("on", "")), input,
+		url.Values{"A": {"on"}, "C": {"yes"}})
+}
+
+func TestUnmarshal_BoolFormat(t *testing.T) {
+	type Flags struct {
+		A bool   `qs:"a,bool=yes|no"`
+		B bool   `qs:"b,bool=yes|no"`
+		C bool   `qs:"c,bool=on|"`
+		D []bool `qs:"d,comma,int"`
+		E bool   `qs:"e,bool=yes|no"`
+	}
+	values := url.Values{"a": {"yes"}, "b": {"no"}, "d": {"1,0"}, "e": {"true"}}
+	want := Flags{A: true, D: []bool{true, false}, E: true}
+
+	var got Flags
+	if err := Unmarshal(values, &got); err != nil {
+		t.Fatalf("Unmarshal(%v) returned error: %v", values, err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unmarshal(%v) mismatch:\n%s", values, diff)
+	}
+
+	if err := Unmarshal(url.Values{"a": {"maybe"}}, &got); err == nil {
+		t.Errorf("Unmarshal with invalid bool did not return error")
+	}
+
+	var v struct{ A bool }
+	if err := NewDecoder(WithBoolFormat("y", "n")).Unmarshal(url.Values{"A": {"y"}}, &v); err != nil || !v.A {
+		t.Errorf("Unmarshal with WithBoolFormat = %v, %v, want true", v.A, err)
+	}
+}
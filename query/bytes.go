@@ -0,0 +1,121 @@
+package query
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+)
+
+// BytesFormat 字节切片、字节数组的编码格式
+type BytesFormat int
+
+const (
+	// BytesElements 与其他切片相同，每个字节作为一个元素，默认格式
+	BytesElements BytesFormat = iota
+	// BytesBase64 标准base64编码，带填充
+	BytesBase64
+	// BytesBase64URL URL安全的base64编码，带填充
+	BytesBase64URL
+	// BytesBase64Raw 标准base64编码，不带填充
+	BytesBase64Raw
+	// BytesBase64URLRaw URL安全的base64编码，不带填充
+	BytesBase64URLRaw
+	// BytesHex 十六进制编码
+	BytesHex
+	// BytesRaw 字节原样作为字符串
+	BytesRaw
+)
+
+// bytesFormatOptions 标签选项与字节编码格式的对应关系
+var bytesFormatOptions = map[string]BytesFormat{
+	"base64":       BytesBase64,
+	"base64url":    BytesBase64URL,
+	"base64raw":    BytesBase64Raw,
+	"base64urlraw": BytesBase64URLRaw,
+	"hex":          BytesHex,
+	"raw":          BytesRaw,
+}
+
+// base64Encoding 返回base64格式对应的编码，非base64格式返回nil
+func (f BytesFormat) base64Encoding() *base64.Encoding {
+	switch f {
+	case BytesBase64:
+		return base64.StdEncoding
+	case BytesBase64URL:
+		return base64.URLEncoding
+	case BytesBase64Raw:
+		return base64.RawStdEncoding
+	case BytesBase64URLRaw:
+		return base64.RawURLEncoding
+	}
+	return nil
+}
+
+// encode 将字节编码为字符串
+func (f BytesFormat) encode(b []byte) string {
+	if enc := f.base64Encoding(); enc != nil {
+		return enc.EncodeToString(b)
+	}
+	if f == BytesHex {
+		return hex.EncodeToString(b)
+	}
+	return string(b)
+}
+
+// decode 将字符串解码为字节
+func (f BytesFormat) decode(s string) ([]byte, error) {
+	if enc := f.base64Encoding(); enc != nil {
+		return enc.DecodeString(s)
+	}
+	if f == BytesHex {
+		return hex.DecodeString(s)
+	}
+	return []byte(s), nil
+}
+
+// bytesFormatOf 返回当前域使用的字节编码格式，元素不是字节的类型返回BytesElements
+func (o *options) bytesFormatOf(scope ScopeOptions, t reflect.Type) BytesFormat {
+	if t.Elem().Kind() != reflect.Uint8 {
+		return BytesElements
+	}
+	if scope.format != nil && scope.format.bytes != BytesElements {
+		return scope.format.bytes
+	}
+	return o.bytesFormat
+}
+
+// bytesOf 复制字节切片、字节数组的内容，元素可以是以uint8为底层类型的自定义类型
+func bytesOf(val reflect.Value) []byte {
+	b := make([]byte, val.Len())
+	for i := range b {
+		b[i] = byte(val.Index(i).Uint())
+	}
+	return b
+}
+
+// bytesDecode 将单个值解码为字节切片、字节数组，数组的长度需与解码结果一致
+func (d *QueryDecoder) bytesDecode(scope ScopeOptions, node *keyNode, val reflect.Value, format BytesFormat) error {
+	s, ok := node.value()
+	if !ok {
+		return nil
+	}
+	b, err := format.decode(s)
+	if err != nil {
+		return fmt.Errorf("invalid bytes value of %s: %v", scope.Scope, err)
+	}
+	if val.Kind() == reflect.Array {
+		if len(b) == 0 {
+			return nil
+		}
+		if len(b) != val.Len() {
+			return fmt.Errorf("%s: decoded %d bytes for array of length %v", scope.Scope, len(b), val.Len())
+		}
+	} else {
+		val.Set(reflect.MakeSlice(val.Type(), len(b), len(b)))
+	}
+	for i, c := range b {
+		val.Index(i).SetUint(uint64(c))
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package query
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// octet 以uint8为底层类型的自定义类型
+type octet uint8
+
+func TestValues_BytesFormat(t *testing.T) {
+	data := []byte{0xfb, 0xff, 0x01}
+	tests := []struct {
+		input interface{}
+		want  url.Values
+	}{
+		{
+			struct {
+				A []byte `qs:"a,base64"`
+				B []byte `qs:"b,base64url"`
+				C []byte `qs:"c,base64raw"`
+				D []byte `qs:"d,base64urlraw"`
+				E []byte `qs:"e,hex"`
+				F []byte `qs:"f,raw"`
+			}{data, data, []byte{1}, []byte{1}, data, []byte("a b")},
+			url.Values{
+				"a": {"+/8B"},
+				"b": {"-_8B"},
+				"c": {"AQ"},
+				"d": {"AQ"},
+				"e": {"fbff01"},
+				"f": {"a b"},
+			},
+		},
+		{
+			struct {
+				ID   [4]byte           `qs:"id,hex"`
+				O    []octet           `qs:"o,hex"`
+				L    []byte            `qs:"l"`
+				Ints []int             `qs:"ints,hex"`
+				Many [][]byte          `qs:"many,base64"`
+				Nil  []byte            `qs:"nil,base64"`
+				M    map[string][]byte `qs:"m,hex"`
+			}{
+				[4]byte{0xde, 0xad, 0xbe, 0xef},
+				[]octet{0x0a},
+				[]byte{1, 2},
+				[]int{1},
+				[][]byte{{1}, {2}},
+				nil,
+				map[string][]byte{"k": {0xff}},
+			},
+			url.Values{
+				"id":      {"deadbeef"},
+				"o":       {"0a"},
+				"l[0]":    {"1"},
+				"l[1]":    {"2"},
+				"ints[0]": {"1"},
+				"many[0]": {"AQ=="},
+				"many[1]": {"Ag=="},
+				"m[k]":    {"ff"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		testValue(t, tt.input, tt.want)
+	}
+
+	testEncoderValue(t, NewEncoder(WithBytesFormat(BytesBase64URLRaw)), struct {
+		A []byte
+		B []byte `qs:"B,hex"`
+	}{data, data}, url.Values{"A": {"-_8B"}, "B": {"fbff01"}})
+}
+
+func TestUnmarshal_BytesFormat(t *testing.T) {
+	type Binary struct {
+		A  []byte   `qs:"a,base64"`
+		B  []byte   `qs:"b,base64urlraw"`
+		ID [4]byte  `qs:"id,hex"`
+		O  []octet  `qs:"o,hex"`
+		R  []byte   `qs:"r,raw"`
+		L  []byte   `qs:"l"`
+		Z  [2]byte  `qs:"z,hex"`
+		M  [][]byte `qs:"m,hex"`
+	}
+	input := Binary{
+		A:  []byte{0xfb, 0xff},
+		B:  []byte{0xfb, 0xff},
+		ID: [4]byte{1, 2, 3, 4},
+		O:  []octet{9},
+		R:  []byte("x y"),
+		L:  []byte{7},
+		M:  [][]byte{{1}, {2, 3}},
+	}
+	values, err := Values(input)
+	if err != nil {
+		t.Fatalf("Values returned error: %v", err)
+	}
+	var got Binary
+	if err := Unmarshal(values, &got); err != nil {
+		t.Fatalf("Unmarshal(%v) returned error: %v", values, err)
+	}
+	if diff := cmp.Diff(input, got); diff != "" {
+		t.Errorf("Unmarshal(%v) mismatch:\n%s", values, diff)
+	}
+
+	for _, values := range []url.Values{
+		{"a": {"%%%"}},
+		{"id": {"zz"}},
+		{"id": {"0102"}},
+	} {
+		if err := Unmarshal(values, &got); err == nil {
+			t.Errorf("Unmarshal(%v) did not return error", values)
+		}
+	}
+}
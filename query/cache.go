@@ -0,0 +1,180 @@
+package query
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+var textMarshalerType = reflect.TypeOf(new(encoding.TextMarshaler)).Elem()
+var stringerType = reflect.TypeOf(new(fmt.Stringer)).Elem()
+
+// typeCache 缓存每个类型的编码信息，key为typeKey，value为*typeInfo
+var typeCache sync.Map
+
+// typeKey 字段信息与读取的标签名称相关，缓存时需要一并区分
+type typeKey struct {
+	typ    reflect.Type
+	tagKey string
+}
+
+// typeInfo 类型的编码信息，由反射解析一次后缓存，重复编码同一类型时只需读取值
+type typeInfo struct {
+	isTime    bool
+	isEncoder bool
+	// 作为单个值编码的标准库类型，如time.Duration、url.URL
+	std *stdCodec
+	// 值或指针实现了encoding.TextMarshaler、fmt.Stringer，指针上的方法仅在值可取地址时使用
+	textMarshaler    bool
+	ptrTextMarshaler bool
+	stringer         bool
+	ptrStringer      bool
+	// 值或指针实现了driver.Valuer
+	valuer    bool
+	ptrValuer bool
+	// 值实现了fmt.Formatter，有格式选项的浮点数、布尔值也由fmt输出
+	formatter bool
+	// 结构体的字段，非结构体为空
+	fields []fieldInfo
+}
+
+// fieldInfo 结构体字段的编码信息
+type fieldInfo struct {
+	index int
+	name  string
+	// Go字段名
+	fieldName string
+	opts      tagOptions
+	// 匿名嵌入的结构体或结构体指针，其字段展开到上一层
+	embedded  bool
+	omitEmpty bool
+	omitZero  bool
+	omitNil   bool
+	// omitempty=deep，编码结果没有参数时忽略
+	omitDeep bool
+	// 字段值的格式选项，未指定时为nil
+	format *fieldFormat
+	// 解码时的校验规则，未指定时为nil，规则格式错误时在解码时返回rulesErr
+	rules    *fieldRules
+	rulesErr error
+	// 解码时参数不存在使用的默认值，切片的多个元素以|分隔
+	defaults []string
+}
+
+// cachedTypeInfo 获取类型的编码信息，不存在时解析并缓存
+func cachedTypeInfo(t reflect.Type, tagKey string) *typeInfo {
+	key := typeKey{t, tagKey}
+	if ti, ok := typeCache.Load(key); ok {
+		return ti.(*typeInfo)
+	}
+	ti, _ := typeCache.LoadOrStore(key, newTypeInfo(t, tagKey))
+	return ti.(*typeInfo)
+}
+
+// newTypeInfo 解析类型的编码信息
+func newTypeInfo(t reflect.Type, tagKey string) *typeInfo {
+	ti := &typeInfo{
+		isTime:    t == timeType,
+		isEncoder: t.Implements(encoderType),
+		std:       stdCodecs[t],
+	}
+	// 接口类型的值在取出实际的值后再判断
+	if t.Kind() != reflect.Interface {
+		ti.textMarshaler = t.Implements(textMarshalerType)
+		ti.ptrTextMarshaler = reflect.PtrTo(t).Implements(textMarshalerType)
+		ti.stringer = t.Implements(stringerType)
+		ti.ptrStringer = reflect.PtrTo(t).Implements(stringerType)
+		ti.valuer = t.Implements(valuerType)
+		ti.ptrValuer = reflect.PtrTo(t).Implements(valuerType)
+		ti.formatter = t.Implements(formatterType)
+	}
+	if t.Kind() != reflect.Struct {
+		return ti
+	}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		// 私有字段
+		if sf.PkgPath != "" && !sf.Anonymous {
+			continue
+		}
+		tag := sf.Tag.Get(tagKey)
+		// 忽略掉该字段
+		if tag == "-" {
+			continue
+		}
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		// 嵌套结构体
+		if sf.Anonymous {
+			if ft.Kind() == reflect.Struct {
+				ti.fields = append(ti.fields, fieldInfo{index: i, embedded: true})
+				continue
+			}
+			// 私有的非结构体类型无法取值
+			if sf.PkgPath != "" {
+				continue
+			}
+		}
+		name, opts := parseTag(tag)
+		if name == "" {
+			name = sf.Name
+		}
+		rules, rulesErr := parseFieldRules(opts)
+		ti.fields = append(ti.fields, fieldInfo{
+			index:     i,
+			name:      name,
+			fieldName: sf.Name,
+			opts:      opts,
+			omitEmpty: opts.Contains("omitempty") || opts.Contains("omitempty=deep"),
+			omitDeep:  opts.Contains("omitempty=deep"),
+			omitZero:  opts.Contains("omitzero"),
+			omitNil:   opts.Contains("omitnil"),
+			format:    parseFieldFormat(opts),
+			rules:     rules,
+			rulesErr:  rulesErr,
+			defaults:  parseDefault(opts, ft),
+		})
+	}
+	return ti
+}
+
+// parseDefault 解析标签选项default=，切片、数组的多个元素以|分隔，没有默认值时返回nil
+func parseDefault(opts tagOptions, t reflect.Type) []string {
+	for _, opt := range opts {
+		if !strings.HasPrefix(opt, "default=") {
+			continue
+		}
+		s := strings.TrimPrefix(opt, "default=")
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			return strings.Split(s, "|")
+		}
+		return []string{s}
+	}
+	return nil
+}
+
+// textMarshalerOf 返回值或其指针实现的encoding.TextMarshaler
+func (ti *typeInfo) textMarshalerOf(val reflect.Value) (encoding.TextMarshaler, bool) {
+	if ti.textMarshaler {
+		return val.Interface().(encoding.TextMarshaler), true
+	}
+	if ti.ptrTextMarshaler && val.CanAddr() {
+		return val.Addr().Interface().(encoding.TextMarshaler), true
+	}
+	return nil, false
+}
+
+// stringerOf 返回值或其指针实现的fmt.Stringer
+func (ti *typeInfo) stringerOf(val reflect.Value) (fmt.Stringer, bool) {
+	if ti.stringer {
+		return val.Interface().(fmt.Stringer), true
+	}
+	if ti.ptrStringer && val.CanAddr() {
+		return val.Addr().Interface().(fmt.Stringer), true
+	}
+	return nil, false
+}
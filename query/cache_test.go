@@ -0,0 +1,75 @@
+package query
+
+import (
+	"net/url"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type unexportedInt int
+
+func TestCachedTypeInfo(t *testing.T) {
+	type Inner struct {
+		V string
+	}
+	type S struct {
+		Inner
+		*Level
+		unexportedInt
+		ID    int `qs:"-"`
+		Name  string
+		Age   int `qs:"age,omitempty"`
+		Time  time.Time
+		inner string
+	}
+
+	ti := cachedTypeInfo(reflect.TypeOf(S{}), defaultTagKey)
+	want := []fieldInfo{
+		{index: 0, embedded: true},
+		{index: 1, embedded: true},
+		{index: 4, name: "Name", fieldName: "Name", opts: tagOptions{}},
+		{index: 5, name: "age", fieldName: "Age", opts: tagOptions{"omitempty"}, omitEmpty: true},
+		{index: 6, name: "Time", fieldName: "Time", opts: tagOptions{}},
+	}
+	if diff := cmp.Diff(want, ti.fields, cmp.AllowUnexported(fieldInfo{})); diff != "" {
+		t.Errorf("cachedTypeInfo fields mismatch:\n%s", diff)
+	}
+	if ti.isTime || ti.isEncoder {
+		t.Errorf("cachedTypeInfo(S) = %+v, want neither time nor encoder", ti)
+	}
+	if got := cachedTypeInfo(reflect.TypeOf(S{}), defaultTagKey); got != ti {
+		t.Errorf("cachedTypeInfo returned a new value for a cached type")
+	}
+
+	if !cachedTypeInfo(timeType, defaultTagKey).isTime {
+		t.Errorf("cachedTypeInfo(time.Time).isTime = false, want true")
+	}
+	if !cachedTypeInfo(reflect.TypeOf(customEncodedInt(0)), defaultTagKey).isEncoder {
+		t.Errorf("cachedTypeInfo(customEncodedInt).isEncoder = false, want true")
+	}
+	if cachedTypeInfo(reflect.TypeOf(customEncodedIntPtr(0)), defaultTagKey).isEncoder {
+		t.Errorf("cachedTypeInfo(customEncodedIntPtr).isEncoder = true, want false")
+	}
+}
+
+func TestValues_Concurrent(t *testing.T) {
+	type S struct {
+		Name    string   `qs:"name"`
+		Subject []string `qs:"subject"`
+	}
+	want := url.Values{"name": {"n"}, "subject[0]": {"a"}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			testValue(t, S{"n", []string{"a"}}, want)
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,594 @@
+package query
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var decoderType = reflect.TypeOf(new(Decoder)).Elem()
+var textUnmarshalerType = reflect.TypeOf(new(encoding.TextUnmarshaler)).Elem()
+
+// Decoder 自定义解码过程，scope与EncodeValues收到的scope一致
+type Decoder interface {
+	DecodeValues(scope string, v url.Values) error
+}
+
+// implementsDecoder 类型本身或其指针实现了Decoder
+func implementsDecoder(t reflect.Type) bool {
+	return t.Implements(decoderType) || reflect.PtrTo(t).Implements(decoderType)
+}
+
+var decoderFields sync.Map
+
+// containsDecoder 类型本身或其嵌套的结构体字段中存在Decoder
+func containsDecoder(t reflect.Type) bool {
+	if v, ok := decoderFields.Load(t); ok {
+		return v.(bool)
+	}
+	found := findDecoder(t, map[reflect.Type]bool{})
+	decoderFields.Store(t, found)
+	return found
+}
+
+// findDecoder 递归查找Decoder，visited用于避免循环引用的类型
+func findDecoder(t reflect.Type, visited map[reflect.Type]bool) bool {
+	if implementsDecoder(t) {
+		return true
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || visited[t] {
+		return false
+	}
+	visited[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous {
+			continue
+		}
+		if findDecoder(sf.Type, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+var missingRuleFields sync.Map
+
+// containsMissingRules 类型本身或其嵌套的结构体字段中存在required规则或default=默认值，
+// 这样的字段在没有对应参数时也需要解码，以检查规则、填充默认值
+func containsMissingRules(t reflect.Type, tagKey string) bool {
+	key := typeKey{t, tagKey}
+	if v, ok := missingRuleFields.Load(key); ok {
+		return v.(bool)
+	}
+	found := findMissingRules(t, tagKey, map[reflect.Type]bool{})
+	missingRuleFields.Store(key, found)
+	return found
+}
+
+// findMissingRules 递归查找required规则及默认值，visited用于避免循环引用的类型
+func findMissingRules(t reflect.Type, tagKey string, visited map[reflect.Type]bool) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || visited[t] {
+		return false
+	}
+	visited[t] = true
+	for _, f := range cachedTypeInfo(t, tagKey).fields {
+		if f.defaults != nil || (f.rules != nil && f.rules.required) {
+			return true
+		}
+		if findMissingRules(t.Field(f.index).Type, tagKey, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// keyNode 参数名按中括号拆分后形成的树形节点
+type keyNode struct {
+	values   []string
+	children map[string]*keyNode
+}
+
+// child 获取或创建子节点
+func (n *keyNode) child(name string) *keyNode {
+	if n.children == nil {
+		n.children = make(map[string]*keyNode)
+	}
+	c, ok := n.children[name]
+	if !ok {
+		c = &keyNode{}
+		n.children[name] = c
+	}
+	return c
+}
+
+// parseValues 将url.Values解析为树形结构
+func parseValues(values url.Values, split KeySplitter) *keyNode {
+	root := &keyNode{}
+	for key, vs := range values {
+		n := root
+		segs := []string{key}
+		if split != nil {
+			segs = split(key)
+		}
+		for _, seg := range segs {
+			n = n.child(seg)
+		}
+		n.values = append(n.values, vs...)
+	}
+	return root
+}
+
+// defaultDecoder Unmarshal使用的解码器
+var defaultDecoder = NewDecoder()
+
+// QueryDecoder 可配置的解码器，创建后配置不可修改，可在多个goroutine中并发使用
+type QueryDecoder struct {
+	opts options
+}
+
+// NewDecoder 创建解码器
+func NewDecoder(opts ...Option) *QueryDecoder {
+	return &QueryDecoder{
+		opts: newOptions(opts),
+	}
+}
+
+// Unmarshal 使用默认解码器将values解码到v中，v必须为非nil的指针
+// 参数名的格式与Values的输出一致，如 nest[a][value]、subject[2]
+func Unmarshal(values url.Values, v interface{}) error {
+	return defaultDecoder.Unmarshal(values, v)
+}
+
+// Unmarshal 将values解码到v中，v必须为非nil的指针
+func (d *QueryDecoder) Unmarshal(values url.Values, v interface{}) error {
+	val := reflect.ValueOf(v)
+	if val.Kind() != reflect.Ptr || val.IsNil() {
+		return fmt.Errorf("unmarshal target must be a non-nil pointer, get: %v", reflect.TypeOf(v))
+	}
+	val = val.Elem()
+	kind := val.Kind()
+	if kind == reflect.Ptr {
+		kind = val.Type().Elem().Kind()
+	}
+	if kind != reflect.Struct && kind != reflect.Array && kind != reflect.Slice && kind != reflect.Map && kind != reflect.Interface && !implementsDecoder(val.Type()) {
+		return fmt.Errorf("unexpects kind: %v", kind)
+	}
+	scope := ScopeOptions{
+		Scope: "",
+		Level: 1,
+	}
+	return d.valueDecode(scope, values, parseValues(values, d.opts.splitter), val)
+}
+
+// valueDecode 将节点解码到val中
+func (d *QueryDecoder) valueDecode(scope ScopeOptions, values url.Values, node *keyNode, val reflect.Value) error {
+	if d.opts.maxLevel > 0 && scope.Level > d.opts.maxLevel {
+		return fmt.Errorf("recurse level too deep, the max is: %v", d.opts.maxLevel)
+	}
+	if val.Kind() == reflect.Ptr {
+		// 标准库类型的指针直接指向解析结果
+		if c, ok := stdCodecs[val.Type().Elem()]; ok {
+			return d.stdDecode(scope, node, val, c)
+		}
+		if val.IsNil() {
+			val.Set(reflect.New(val.Type().Elem()))
+		}
+		if val.Type().Implements(decoderType) {
+			return val.Interface().(Decoder).DecodeValues(scope.Scope, values)
+		}
+		return d.valueDecode(scope, values, node, val.Elem())
+	}
+
+	// 自定义Decode方法
+	if val.CanAddr() && val.Addr().Type().Implements(decoderType) {
+		return val.Addr().Interface().(Decoder).DecodeValues(scope.Scope, values)
+	}
+	if val.Kind() != reflect.Interface && val.Type().Implements(decoderType) {
+		if val.Kind() == reflect.Map && val.IsNil() {
+			val.Set(reflect.MakeMap(val.Type()))
+		}
+		return val.Interface().(Decoder).DecodeValues(scope.Scope, values)
+	}
+
+	// 时间格式
+	if val.Type() == timeType {
+		s, ok := node.value()
+		if !ok {
+			return nil
+		}
+		t, err := parseTime(s, d.opts.timeLayoutOf(scope))
+		if err != nil {
+			return fmt.Errorf("invalid time value of %s: %v", scope.Scope, err)
+		}
+		val.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	// 标准库类型
+	if c, ok := stdCodecs[val.Type()]; ok {
+		return d.stdDecode(scope, node, val, c)
+	}
+
+	// 实现了encoding.TextUnmarshaler的类型
+	if val.CanAddr() && val.Addr().Type().Implements(textUnmarshalerType) {
+		s, ok := node.value()
+		if !ok {
+			return nil
+		}
+		if err := val.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			return fmt.Errorf("invalid value of %s: %v", scope.Scope, err)
+		}
+		return nil
+	}
+
+	// 实现了sql.Scanner的类型
+	if val.CanAddr() && val.Addr().Type().Implements(scannerType) {
+		return d.scannerDecode(scope, values, node, val)
+	}
+
+	switch val.Kind() {
+	case reflect.Struct:
+		return d.structDecode(scope, values, node, val)
+	case reflect.Slice:
+		return d.sliceDecode(scope, values, node, val)
+	case reflect.Array:
+		return d.arrayDecode(scope, values, node, val)
+	case reflect.Map:
+		return d.mapDecode(scope, values, node, val)
+	case reflect.Interface:
+		return d.interfaceDecode(scope, values, node, val)
+	}
+	s, ok := node.value()
+	if !ok {
+		return nil
+	}
+	return d.scalarDecode(scope, s, val)
+}
+
+// value 取节点的第一个值
+func (n *keyNode) value() (string, bool) {
+	if len(n.values) == 0 {
+		return "", false
+	}
+	return n.values[0], true
+}
+
+// scalarDecode 解析基础类型的值
+func (d *QueryDecoder) scalarDecode(scope ScopeOptions, s string, val reflect.Value) error {
+	switch val.Kind() {
+	case reflect.String:
+		val.SetString(s)
+	case reflect.Bool:
+		b, err := d.opts.boolStyleOf(scope).parseBool(s)
+		if err != nil {
+			return fmt.Errorf("invalid bool value of %s: %q", scope.Scope, s)
+		}
+		val.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, val.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid int value of %s: %q", scope.Scope, s)
+		}
+		val.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 10, val.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid uint value of %s: %q", scope.Scope, s)
+		}
+		val.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, val.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid float value of %s: %q", scope.Scope, s)
+		}
+		val.SetFloat(n)
+	default:
+		return fmt.Errorf("unexpects kind: %v", val.Kind())
+	}
+	return nil
+}
+
+// structDecode 解码结构体，字段规则与structEncode一致
+func (d *QueryDecoder) structDecode(scope ScopeOptions, values url.Values, node *keyNode, val reflect.Value) error {
+	path := scope.path
+	for _, f := range cachedTypeInfo(val.Type(), d.opts.tagKey).fields {
+		sv := val.Field(f.index)
+		// 嵌套结构体
+		if f.embedded {
+			if err := d.optionalDecode(scope, values, node, sv); err != nil {
+				return err
+			}
+			continue
+		}
+		fieldScope := ScopeOptions{
+			Scope:  d.opts.joiner(scope.Scope, f.name, false),
+			Level:  scope.Level + 1,
+			format: f.format,
+			path:   path.field(f.fieldName),
+		}
+		if f.rulesErr != nil {
+			return fmt.Errorf("%s: %v", fieldScope.Scope, f.rulesErr)
+		}
+		child, ok := node.children[f.name]
+		if !ok && f.defaults != nil {
+			child, ok = &keyNode{values: f.defaults}, true
+		}
+		if f.rules != nil && f.rules.required && (!ok || !child.present()) {
+			return validationError(fieldScope, "required", "is required")
+		}
+		if !ok {
+			// 自定义解码的字段可能使用其他格式的参数名，如 v.0，
+			// 嵌套结构体的字段可能有required规则或默认值，使用空节点解码
+			if !containsDecoder(sv.Type()) && !containsMissingRules(sv.Type(), d.opts.tagKey) {
+				continue
+			}
+			if fieldScope.Level > d.syntheticLevel() {
+				continue
+			}
+			if err := d.optionalDecode(fieldScope, values, &keyNode{}, sv); err != nil {
+				return err
+			}
+			continue
+		}
+		err := d.valueDecode(fieldScope, values, child, sv)
+		if err != nil {
+			return err
+		}
+		if f.rules != nil {
+			if err := f.rules.validate(fieldScope, sv); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// syntheticLevel 没有对应参数时向下查找Decoder的最大层数，
+// 不限制层数时使用默认值，避免循环引用的类型无限递归
+func (d *QueryDecoder) syntheticLevel() int {
+	if d.opts.maxLevel > 0 {
+		return d.opts.maxLevel
+	}
+	return maxLevel
+}
+
+// optionalDecode 解码可能没有对应参数的值，nil指针仅在解码出内容时才会被赋值
+func (d *QueryDecoder) optionalDecode(scope ScopeOptions, values url.Values, node *keyNode, sv reflect.Value) error {
+	if sv.Kind() != reflect.Ptr {
+		return d.valueDecode(scope, values, node, sv)
+	}
+	if !sv.IsNil() {
+		return d.valueDecode(scope, values, node, sv.Elem())
+	}
+	if !sv.CanSet() {
+		return nil
+	}
+	v := reflect.New(sv.Type().Elem())
+	if err := d.valueDecode(scope, values, node, v.Elem()); err != nil {
+		return err
+	}
+	if !v.Elem().IsZero() {
+		sv.Set(v)
+	}
+	return nil
+}
+
+// element 数组元素及其下标
+type element struct {
+	index int
+	node  *keyNode
+}
+
+// elements 返回节点中的数组元素，带下标的参数按下标排序，
+// 没有下标的重复参数及空中括号的参数按出现顺序作为元素，指定分隔符时对每个值进行拆分
+func (n *keyNode) elements(delimiter string) ([]element, error) {
+	if len(n.children) == 0 {
+		var elems []element
+		for _, s := range n.values {
+			if delimiter == "" {
+				elems = append(elems, element{len(elems), &keyNode{values: []string{s}}})
+				continue
+			}
+			for _, part := range strings.Split(s, delimiter) {
+				elems = append(elems, element{len(elems), &keyNode{values: []string{part}}})
+			}
+		}
+		return elems, nil
+	}
+	// ids[]=1&ids[]=2
+	if c, ok := n.children[""]; ok && len(n.children) == 1 && len(c.children) == 0 {
+		return c.elements(delimiter)
+	}
+	elems := make([]element, 0, len(n.children))
+	for k, c := range n.children {
+		i, err := strconv.Atoi(k)
+		if err != nil || i < 0 {
+			return nil, fmt.Errorf("invalid index: %q", k)
+		}
+		elems = append(elems, element{i, c})
+	}
+	sort.Slice(elems, func(i, j int) bool {
+		return elems[i].index < elems[j].index
+	})
+	return elems, nil
+}
+
+// elementDecode 解码下标为i的元素，path为scope.path的副本，元素的路径引用它
+func (d *QueryDecoder) elementDecode(scope ScopeOptions, path *fieldPath, values url.Values, elem element, val reflect.Value) error {
+	return d.valueDecode(ScopeOptions{
+		Scope:  d.opts.joiner(scope.Scope, strconv.Itoa(elem.index), true),
+		Level:  scope.Level + 1,
+		format: scope.format,
+		path:   path.index(elem.index),
+	}, values, elem.node, val)
+}
+
+// sliceDecode 解码切片，元素按下标放置，缺失的下标为零值，缺失的个数超过maxSliceHoles时返回错误
+func (d *QueryDecoder) sliceDecode(scope ScopeOptions, values url.Values, node *keyNode, val reflect.Value) error {
+	if f := d.opts.bytesFormatOf(scope, val.Type()); f != BytesElements {
+		return d.bytesDecode(scope, node, val, f)
+	}
+	_, delimiter := d.opts.arrayStyle(scope)
+	elems, err := node.elements(delimiter)
+	if err != nil {
+		return fmt.Errorf("%s: %v", scope.Scope, err)
+	}
+	if len(elems) == 0 {
+		return nil
+	}
+	n := elems[len(elems)-1].index + 1
+	if n-len(elems) > d.opts.maxSliceHoles {
+		return fmt.Errorf("%s: index %d leaves %d missing elements, the max is: %v", scope.Scope, n-1, n-len(elems), d.opts.maxSliceHoles)
+	}
+	s := reflect.MakeSlice(val.Type(), n, n)
+	path := scope.path
+	for _, elem := range elems {
+		if err := d.elementDecode(scope, &path, values, elem, s.Index(elem.index)); err != nil {
+			return err
+		}
+	}
+	val.Set(s)
+	return nil
+}
+
+// arrayDecode 解码数组，元素按下标放置，超出数组长度的下标返回错误
+func (d *QueryDecoder) arrayDecode(scope ScopeOptions, values url.Values, node *keyNode, val reflect.Value) error {
+	if f := d.opts.bytesFormatOf(scope, val.Type()); f != BytesElements {
+		return d.bytesDecode(scope, node, val, f)
+	}
+	_, delimiter := d.opts.arrayStyle(scope)
+	elems, err := node.elements(delimiter)
+	if err != nil {
+		return fmt.Errorf("%s: %v", scope.Scope, err)
+	}
+	if len(elems) > 0 && elems[len(elems)-1].index >= val.Len() {
+		return fmt.Errorf("%s: index %d out of range for array of length %v", scope.Scope, elems[len(elems)-1].index, val.Len())
+	}
+	path := scope.path
+	for _, elem := range elems {
+		if err := d.elementDecode(scope, &path, values, elem, val.Index(elem.index)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mapDecode 解码map，key的规则与mapKeyString一致
+func (d *QueryDecoder) mapDecode(scope ScopeOptions, values url.Values, node *keyNode, val reflect.Value) error {
+	typ := val.Type()
+	if !isMapKeyType(typ.Key()) {
+		return fmt.Errorf("unsupported kind of map key: %v", typ.Key().Kind())
+	}
+	if len(node.children) == 0 {
+		return nil
+	}
+	if val.IsNil() {
+		val.Set(reflect.MakeMapWithSize(typ, len(node.children)))
+	}
+	path := scope.path
+	for key, child := range node.children {
+		v := reflect.New(typ.Elem()).Elem()
+		err := d.valueDecode(ScopeOptions{
+			Scope:  d.opts.joiner(scope.Scope, key, false),
+			Level:  scope.Level + 1,
+			format: scope.format,
+			path:   path.key(key),
+		}, values, child, v)
+		if err != nil {
+			return err
+		}
+		k, err := mapKeyValue(typ.Key(), key)
+		if err != nil {
+			return fmt.Errorf("invalid map key of %s: %v", scope.Scope, err)
+		}
+		val.SetMapIndex(k, v)
+	}
+	return nil
+}
+
+// isMapKeyType 判断类型能否作为解码的map key
+func isMapKeyType(t reflect.Type) bool {
+	if t.Kind() == reflect.String || reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Bool:
+		return true
+	}
+	return false
+}
+
+// mapKeyValue 将参数名解析为map的key
+func mapKeyValue(t reflect.Type, s string) (reflect.Value, error) {
+	k := reflect.New(t).Elem()
+	if t.Kind() == reflect.String {
+		k.SetString(s)
+		return k, nil
+	}
+	if m, ok := k.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		if err := m.UnmarshalText([]byte(s)); err != nil {
+			return k, err
+		}
+		return k, nil
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, t.Bits())
+		if err != nil {
+			return k, err
+		}
+		k.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 10, t.Bits())
+		if err != nil {
+			return k, err
+		}
+		k.SetUint(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return k, err
+		}
+		k.SetBool(b)
+	}
+	return k, nil
+}
+
+// interfaceDecode 解码interface{}，已有指针值时解码到该指针，
+// 否则叶子节点解码为string，其余解码为map[string]interface{}
+func (d *QueryDecoder) interfaceDecode(scope ScopeOptions, values url.Values, node *keyNode, val reflect.Value) error {
+	if !val.IsNil() && val.Elem().Kind() == reflect.Ptr && !val.Elem().IsNil() {
+		return d.valueDecode(scope, values, node, val.Elem())
+	}
+	if val.NumMethod() != 0 {
+		return fmt.Errorf("%s: cannot decode into non-empty interface %v", scope.Scope, val.Type())
+	}
+	if len(node.children) == 0 {
+		s, ok := node.value()
+		if ok {
+			val.Set(reflect.ValueOf(s))
+		}
+		return nil
+	}
+	m := make(map[string]interface{})
+	v := reflect.ValueOf(&m).Elem()
+	if err := d.mapDecode(scope, values, node, v); err != nil {
+		return err
+	}
+	val.Set(v)
+	return nil
+}
@@ -0,0 +1,542 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// test that Unmarshal(input) into a new value of want's type matches want.
+func testUnmarshal(t *testing.T, input url.Values, want interface{}) {
+	got := reflect.New(reflect.TypeOf(want))
+	if err := Unmarshal(input, got.Interface()); err != nil {
+		t.Errorf("Unmarshal(%v) returned error: %v", input, err)
+	}
+	exporter := cmp.Exporter(func(reflect.Type) bool { return true })
+	if diff := cmp.Diff(want, got.Elem().Interface(), exporter); diff != "" {
+		t.Errorf("Unmarshal(%v) mismatch:\n%s", input, diff)
+	}
+}
+
+func TestUnmarshal_BasicTypes(t *testing.T) {
+	tests := []struct {
+		input url.Values
+		want  interface{}
+	}{
+		{url.Values{"V": {"v"}}, struct{ V string }{"v"}},
+		{url.Values{"V": {"-1"}}, struct{ V int }{-1}},
+		{url.Values{"V": {"1"}}, struct{ V int8 }{1}},
+		{url.Values{"V": {"1"}}, struct{ V uint }{1}},
+		{url.Values{"V": {"0.1"}}, struct{ V float32 }{0.1}},
+		{url.Values{"V": {"true"}}, struct{ V bool }{true}},
+		{url.Values{"V": {"a", "b"}}, struct{ V string }{"a"}},
+		{url.Values{}, struct{ V string }{}},
+		{
+			url.Values{"V": {"2000-01-01 12:34:56"}},
+			struct{ V time.Time }{time.Date(2000, 1, 1, 12, 34, 56, 0, time.UTC)},
+		},
+	}
+
+	for _, tt := range tests {
+		testUnmarshal(t, tt.input, tt.want)
+	}
+}
+
+func TestUnmarshal_Pointers(t *testing.T) {
+	str := "s"
+	strPtr := &str
+
+	tests := []struct {
+		input url.Values
+		want  interface{}
+	}{
+		{url.Values{}, struct{ V *string }{}},
+		{url.Values{"V": {"s"}}, struct{ V *string }{&str}},
+		{url.Values{"V": {"s"}}, struct{ V **string }{&strPtr}},
+		{url.Values{"V[0]": {"s"}, "V[1]": {"s"}}, struct{ V []*string }{[]*string{&str, &str}}},
+		{url.Values{"V[0]": {"a"}, "V[1]": {"b"}}, struct{ V *[]string }{&[]string{"a", "b"}}},
+		{url.Values{"V": {"v"}}, &struct{ V string }{"v"}},
+	}
+
+	for _, tt := range tests {
+		testUnmarshal(t, tt.input, tt.want)
+	}
+}
+
+func TestUnmarshal_Slices(t *testing.T) {
+	tests := []struct {
+		input url.Values
+		want  interface{}
+	}{
+		{url.Values{}, struct{ V []string }{}},
+		{url.Values{"V[0]": {""}}, struct{ V []string }{[]string{""}}},
+		{url.Values{"V[0]": {"a"}, "V[1]": {"b"}}, struct{ V []string }{[]string{"a", "b"}}},
+		// elements are placed at their indexes, missing indexes are zero values
+		{url.Values{"V[3]": {"b"}, "V[1]": {"a"}}, struct{ V []string }{[]string{"", "a", "", "b"}}},
+		// repeated keys without index
+		{url.Values{"V": {"a", "b"}}, struct{ V []string }{[]string{"a", "b"}}},
+		{url.Values{"V[0]": {"1"}, "V[1]": {"2"}}, struct{ V []int }{[]int{1, 2}}},
+		// arrays
+		{url.Values{"V[0]": {"a"}, "V[1]": {"b"}}, struct{ V [2]string }{[2]string{"a", "b"}}},
+		{url.Values{"V[0]": {"a"}}, struct{ V [2]string }{[2]string{"a", ""}}},
+		{url.Values{"V[1]": {"b"}}, struct{ V [2]string }{[2]string{"", "b"}}},
+	}
+
+	for _, tt := range tests {
+		testUnmarshal(t, tt.input, tt.want)
+	}
+}
+
+func TestUnmarshal_NestedTypes(t *testing.T) {
+	type SubNested struct {
+		Value string `qs:"value"`
+	}
+
+	type Nested struct {
+		A   SubNested  `qs:"a"`
+		B   *SubNested `qs:"b"`
+		Ptr *SubNested `qs:"ptr,omitempty"`
+	}
+
+	type Wrapper struct {
+		Nest Nested `qs:"nest"`
+	}
+
+	tests := []struct {
+		input url.Values
+		want  interface{}
+	}{
+		{
+			url.Values{"nest[a][value]": {"v"}},
+			Wrapper{Nested{A: SubNested{Value: "v"}}},
+		},
+		{
+			url.Values{"nest[a][value]": {""}, "nest[ptr][value]": {"v"}},
+			Wrapper{Nested{Ptr: &SubNested{Value: "v"}}},
+		},
+		{
+			url.Values{"list[0][value]": {"a"}, "list[1][value]": {"b"}},
+			struct {
+				List []SubNested `qs:"list"`
+			}{[]SubNested{{"a"}, {"b"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		testUnmarshal(t, tt.input, tt.want)
+	}
+}
+
+func TestUnmarshal_Maps(t *testing.T) {
+	tests := []struct {
+		input url.Values
+		want  interface{}
+	}{
+		{
+			url.Values{"m[a]": {"1"}, "m[b]": {"2"}},
+			struct {
+				M map[string]int `qs:"m"`
+			}{map[string]int{"a": 1, "b": 2}},
+		},
+		{
+			url.Values{"name": {"murong"}, "age[a1]": {"1"}, "age[sex][s3]": {"3"}},
+			map[string]interface{}{
+				"name": "murong",
+				"age": map[string]interface{}{
+					"a1":  "1",
+					"sex": map[string]interface{}{"s3": "3"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		testUnmarshal(t, tt.input, tt.want)
+	}
+}
+
+func TestUnmarshal_Tags(t *testing.T) {
+	type Inner struct {
+		V string
+	}
+	type unexported struct {
+		Inner
+		W string
+	}
+	type Exported struct {
+		unexported
+	}
+
+	tests := []struct {
+		input url.Values
+		want  interface{}
+	}{
+		{
+			url.Values{"ID": {"1"}, "name": {"n"}, "age": {"2"}},
+			struct {
+				ID   int    `qs:"-"`
+				Name string `qs:"name"`
+				Age  int    `qs:"age,omitempty"`
+			}{Name: "n", Age: 2},
+		},
+		{
+			url.Values{"V": {"a"}},
+			struct{ Inner }{Inner{V: "a"}},
+		},
+		{
+			url.Values{"V": {"a"}},
+			struct{ *Inner }{&Inner{V: "a"}},
+		},
+		{
+			url.Values{},
+			struct{ *Inner }{},
+		},
+		{
+			url.Values{"V": {"bar"}, "W": {"foo"}},
+			Exported{unexported{Inner: Inner{V: "bar"}, W: "foo"}},
+		},
+	}
+
+	for _, tt := range tests {
+		testUnmarshal(t, tt.input, tt.want)
+	}
+}
+
+func TestUnmarshal_RoundTrip(t *testing.T) {
+	type Level struct {
+		Level int `qs:"level"`
+	}
+	type Student struct {
+		Level
+		ID       int `qs:"-"`
+		Name     string
+		Age      int               `qs:"age,omitempty"`
+		Subject  []string          `qs:"subject"`
+		Scores   map[string]int    `qs:"scores"`
+		Birthday time.Time         `qs:"birthday"`
+		Parent   *Student          `qs:"parent,omitempty"`
+		Extra    map[string]string `qs:"extra,omitempty"`
+	}
+	want := Student{
+		Level:    Level{Level: 99},
+		Name:     "zhangsan",
+		Age:      1,
+		Subject:  []string{"math", "english", "chinese"},
+		Scores:   map[string]int{"math": 90, "english": 80},
+		Birthday: time.Date(2022, 02, 11, 16, 39, 02, 0, time.UTC),
+		Parent:   &Student{Name: "lisi", Birthday: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	v, err := Values(want)
+	if err != nil {
+		t.Fatalf("Values returned error: %v", err)
+	}
+	var got Student
+	if err := Unmarshal(v, &got); err != nil {
+		t.Fatalf("Unmarshal returned error: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("round trip mismatch:\n%s", diff)
+	}
+}
+
+func TestUnmarshal_RoundTripIndexes(t *testing.T) {
+	one := 1
+	type T struct {
+		A [3]*int
+		S []*int
+	}
+	want := T{A: [3]*int{nil, nil, &one}, S: []*int{nil, &one}}
+	v, err := Values(want)
+	if err != nil {
+		t.Fatalf("Values returned error: %v", err)
+	}
+	var got T
+	if err := Unmarshal(v, &got); err != nil {
+		t.Fatalf("Unmarshal(%v) returned error: %v", v, err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unmarshal(%v) mismatch:\n%s", v, diff)
+	}
+}
+
+func TestUnmarshal_InvalidInput(t *testing.T) {
+	var s struct {
+		V  int
+		A  [1]string
+		L  []string
+		M  map[int]string
+		F  map[float64]string
+		T  time.Time
+		St struct{ B bool }
+	}
+	tests := []url.Values{
+		{"V": {"x"}},
+		{"A[0]": {"a"}, "A[1]": {"b"}},
+		{"A[5]": {"a"}},
+		{"L[101]": {"a"}},
+		{"L[x]": {"a"}},
+		{"M[x]": {"a"}},
+		{"F[1]": {"a"}},
+		{"T": {"yesterday"}},
+		{"St[B]": {"maybe"}},
+	}
+	for _, tt := range tests {
+		if err := Unmarshal(tt, &s); err == nil {
+			t.Errorf("Unmarshal(%v) did not return expected error", tt)
+		}
+	}
+
+	var l struct{ L []string }
+	if err := NewDecoder(WithMaxSliceHoles(0)).Unmarshal(url.Values{"L[1]": {"a"}}, &l); err == nil {
+		t.Errorf("Unmarshal with WithMaxSliceHoles(0) did not return error for a missing index")
+	}
+
+	if err := Unmarshal(url.Values{}, s); err == nil {
+		t.Errorf("expected Unmarshal() to return an error on non-pointer target")
+	}
+	var str string
+	if err := Unmarshal(url.Values{}, &str); err == nil {
+		t.Errorf("expected Unmarshal() to return an error on invalid target kind")
+	}
+}
+
+// DecodeValues reads values with key names of the form "{key}.N", reversing
+// EncodeValues.  A value of "err" will return an error.
+func (m *customEncodedStrings) DecodeValues(key string, v url.Values) error {
+	for i := 0; ; i++ {
+		arg, ok := v[fmt.Sprintf("%s.%d", key, i)]
+		if !ok {
+			return nil
+		}
+		if arg[0] == "err" {
+			return errors.New("decoding error")
+		}
+		*m = append(*m, arg[0])
+	}
+}
+
+func TestUnmarshal_CustomDecodingSlice(t *testing.T) {
+	tests := []struct {
+		input url.Values
+		want  interface{}
+	}{
+		{
+			url.Values{},
+			struct {
+				V customEncodedStrings `qs:"v"`
+			}{},
+		},
+		{
+			url.Values{"v.0": {"a"}, "v.1": {"b"}},
+			struct {
+				V customEncodedStrings `qs:"v"`
+			}{[]string{"a", "b"}},
+		},
+		{
+			url.Values{"n[v].0": {"a"}},
+			struct {
+				N struct {
+					V customEncodedStrings `qs:"v"`
+				} `qs:"n"`
+			}{struct {
+				V customEncodedStrings `qs:"v"`
+			}{[]string{"a"}}},
+		},
+
+		// pointers to custom decoded types
+		{
+			url.Values{},
+			struct {
+				V *customEncodedStrings `qs:"v"`
+			}{},
+		},
+		{
+			url.Values{"v.0": {"a"}, "v.1": {"b"}},
+			struct {
+				V *customEncodedStrings `qs:"v"`
+			}{(*customEncodedStrings)(&[]string{"a", "b"})},
+		},
+	}
+
+	for _, tt := range tests {
+		testUnmarshal(t, tt.input, tt.want)
+	}
+}
+
+// DecodeValues decodes values with leading underscores
+func (m *customEncodedInt) DecodeValues(key string, v url.Values) error {
+	s := strings.TrimPrefix(v.Get(key), "_")
+	if s == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	*m = customEncodedInt(n)
+	return nil
+}
+
+func TestUnmarshal_CustomDecodingInt(t *testing.T) {
+	one := customEncodedInt(1)
+	tests := []struct {
+		input url.Values
+		want  interface{}
+	}{
+		{url.Values{"v": {"_1"}}, struct {
+			V customEncodedInt `qs:"v"`
+		}{one}},
+		{url.Values{"v": {"_1"}}, struct {
+			V *customEncodedInt `qs:"v"`
+		}{&one}},
+		{url.Values{"v[0]": {"_1"}, "v[1]": {"_1"}}, struct {
+			V []customEncodedInt `qs:"v"`
+		}{[]customEncodedInt{one, one}}},
+		{url.Values{"v[a]": {"_1"}}, struct {
+			V map[string]customEncodedInt `qs:"v"`
+		}{map[string]customEncodedInt{"a": one}}},
+	}
+
+	for _, tt := range tests {
+		testUnmarshal(t, tt.input, tt.want)
+	}
+}
+
+func TestUnmarshal_CustomDecoding_Error(t *testing.T) {
+	tests := []struct {
+		input url.Values
+		dst   interface{}
+	}{
+		{url.Values{"V.0": {"err"}}, &struct{ V customEncodedStrings }{}},
+		{url.Values{"S[V].0": {"err"}}, &struct {
+			S struct{ V customEncodedStrings }
+		}{}},
+		{url.Values{"V": {"_x"}}, &struct{ V customEncodedInt }{}},
+	}
+	for _, tt := range tests {
+		if err := Unmarshal(tt.input, tt.dst); err == nil {
+			t.Errorf("Unmarshal(%v) did not return expected decoding error", tt.input)
+		}
+	}
+}
+
+func TestUnmarshal_CustomDecodingRecursive(t *testing.T) {
+	type Node struct {
+		V    customEncodedStrings `qs:"v"`
+		Next *Node                `qs:"next"`
+	}
+	testUnmarshal(t, url.Values{"v.0": {"a"}, "next[v].0": {"b"}}, Node{
+		V:    customEncodedStrings{"a"},
+		Next: &Node{V: customEncodedStrings{"b"}},
+	})
+}
+
+func TestUnmarshal_TextUnmarshaler(t *testing.T) {
+	two := textEnum(2)
+	tests := []struct {
+		input url.Values
+		want  interface{}
+	}{
+		{url.Values{"V": {"one"}}, struct{ V textEnum }{1}},
+		{url.Values{"V": {"two"}}, struct{ V *textEnum }{&two}},
+		{url.Values{"V[0]": {"zero"}, "V[1]": {"one"}}, struct{ V []textEnum }{[]textEnum{0, 1}}},
+		{url.Values{"V[a]": {"two"}}, struct{ V map[string]textEnum }{map[string]textEnum{"a": 2}}},
+	}
+
+	for _, tt := range tests {
+		testUnmarshal(t, tt.input, tt.want)
+	}
+
+	var s struct{ V textEnum }
+	if err := Unmarshal(url.Values{"V": {"nine"}}, &s); err == nil {
+		t.Errorf("expected Unmarshal() to return the UnmarshalText error")
+	}
+}
+
+func TestUnmarshal_MapKeys(t *testing.T) {
+	tests := []struct {
+		input url.Values
+		want  interface{}
+	}{
+		{url.Values{"a": {"1"}}, map[namedKey]int{"a": 1}},
+		{url.Values{"-1": {"a"}, "2": {"b"}}, map[int]string{-1: "a", 2: "b"}},
+		{url.Values{"1": {"a"}}, map[uint8]string{1: "a"}},
+		{url.Values{"true": {"1"}}, map[bool]int{true: 1}},
+		{url.Values{"one": {"true"}}, map[textEnum]bool{1: true}},
+	}
+
+	for _, tt := range tests {
+		testUnmarshal(t, tt.input, tt.want)
+	}
+
+	var m map[uint8]string
+	if err := Unmarshal(url.Values{"256": {"a"}}, &m); err == nil {
+		t.Errorf("expected Unmarshal() to return an error on out of range key")
+	}
+}
+
+func TestUnmarshal_Defaults(t *testing.T) {
+	type Options struct {
+		Page  int       `qs:"page,default=1"`
+		Limit *int      `qs:"limit,default=20,max=50"`
+		Sort  string    `qs:"sort,required,default=desc"`
+		IDs   []int     `qs:"ids,default=1|2"`
+		Tags  []string  `qs:"tags,comma,default=a|b"`
+		Since time.Time `qs:"since,layout=2006-01-02,default=2020-01-02"`
+		Q     string    `qs:"q,default=x"`
+	}
+	limit20, limit30 := 20, 30
+	since := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		input url.Values
+		want  Options
+	}{
+		{
+			url.Values{},
+			Options{1, &limit20, "desc", []int{1, 2}, []string{"a", "b"}, since, "x"},
+		},
+		{
+			// 空值不使用默认值
+			url.Values{"page": {"3"}, "limit": {"30"}, "sort": {"asc"}, "ids[0]": {"5"}, "tags": {"c"}, "q": {""}},
+			Options{3, &limit30, "asc", []int{5}, []string{"c"}, since, ""},
+		},
+	}
+
+	for _, tt := range tests {
+		testUnmarshal(t, tt.input, tt.want)
+	}
+
+	// 嵌套结构体没有对应参数时也使用默认值
+	type Filter struct {
+		Page  int `qs:"page"`
+		Limit int `qs:"limit,default=20"`
+	}
+	type Wrapper struct {
+		F Filter  `qs:"filter"`
+		P *Filter `qs:"p"`
+	}
+	testUnmarshal(t, url.Values{}, Wrapper{Filter{Limit: 20}, &Filter{Limit: 20}})
+	testUnmarshal(t, url.Values{"filter[page]": {"1"}}, Wrapper{Filter{1, 20}, &Filter{Limit: 20}})
+
+	var v struct {
+		Limit int `qs:"limit,default=x"`
+	}
+	if err := Unmarshal(url.Values{}, &v); err == nil {
+		t.Errorf("Unmarshal with invalid default did not return error")
+	}
+	var ve *ValidationError
+	var w struct {
+		Limit int `qs:"limit,default=60,max=50"`
+	}
+	if err := Unmarshal(url.Values{}, &w); !errors.As(err, &ve) {
+		t.Errorf("Unmarshal with out of range default returned error %v, want *ValidationError", err)
+	}
+}
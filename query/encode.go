@@ -1,6 +1,8 @@
 package query
 
 import (
+	"encoding"
+	"errors"
 	"fmt"
 	"net/url"
 	"reflect"
@@ -9,111 +11,307 @@ import (
 	"time"
 )
 
+// maxLevel 默认的最大嵌套层数
 const maxLevel = 7
 
+// cycleCheckLevel 超过该层数后才记录引用检测循环，默认的层数限制内循环引用会先返回ErrTooDeep
+const cycleCheckLevel = maxLevel
+
 var timeType = reflect.TypeOf(time.Time{})
-var timeLayout = "2006-01-02 15:04:05"
 var encoderType = reflect.TypeOf(new(Encoder)).Elem()
 
-// SetTimeFormat 设置时间参数的输出格式
+// defaultEncoder Values使用的编码器
+var defaultEncoder = NewEncoder()
+
+// SetTimeFormat 设置Values、Unmarshal的时间格式
+// 该设置修改的是全局的默认配置，不能与编解码并发调用，需要不同格式时请使用NewEncoder、NewDecoder
 func SetTimeFormat(layout string) {
-	timeLayout = layout
+	defaultEncoder.opts.timeLayout = layout
+	defaultDecoder.opts.timeLayout = layout
 }
 
 // ScopeOptions 域选项
 type ScopeOptions struct {
 	Scope string
 	Level int
+	// 所属字段的格式选项
+	format *fieldFormat
+	// Go字段路径，用于错误信息
+	path fieldPath
+	// omitempty=deep，编码结果没有参数的切片元素、map元素不输出
+	omitDeep bool
 }
 
 type zeroable interface {
 	IsZero() bool
 }
 
+var zeroableType = reflect.TypeOf(new(zeroable)).Elem()
+
 // Encoder 自定义编码过程
 type Encoder interface {
 	EncodeValues(scope string, v *url.Values) error
 }
 
-// Values 对v进行编码，返回url.Values
+// levelEncoder qsgen生成的Encoder同时实现的接口，level为值所在的嵌套层数，
+// 使生成的代码与反射编码使用相同的层数限制
+type levelEncoder interface {
+	EncodeValuesLevel(scope string, v *url.Values, level int) error
+}
+
+// QueryEncoder 可配置的编码器，创建后配置不可修改，可在多个goroutine中并发使用
+type QueryEncoder struct {
+	opts options
+}
+
+// NewEncoder 创建编码器
+func NewEncoder(opts ...Option) *QueryEncoder {
+	return &QueryEncoder{
+		opts: newOptions(opts),
+	}
+}
+
+// Values 使用默认编码器对v进行编码，返回url.Values
 func Values(v interface{}) (url.Values, error) {
+	return defaultEncoder.Values(v)
+}
+
+// Values 对v进行编码，返回url.Values
+func (e *QueryEncoder) Values(v interface{}) (url.Values, error) {
 	values := make(url.Values)
+	if err := e.encode(v, valuesSink(values)); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
 
+// encode 对v进行编码，结果输出到values
+func (e *QueryEncoder) encode(v interface{}, values valueSink) error {
 	if v == nil {
-		return values, nil
+		return nil
 	}
 
 	val := reflect.ValueOf(v)
+	state := &encodeState{
+		QueryEncoder: e,
+		values:       values,
+	}
+
+	scope := ScopeOptions{
+		Scope: "",
+		Level: 1,
+	}
 
 	if val.Kind() == reflect.Ptr {
 		if val.IsNil() {
-			return values, nil
+			return nil
+		}
+		entered, err := state.enter(scope, val)
+		if err != nil {
+			return err
+		}
+		if entered {
+			defer state.leave(val)
 		}
 		val = val.Elem()
 	}
 
-	scope := ScopeOptions{
-		Scope: "",
-		Level: 1,
+	if val.Kind() != reflect.Struct && val.Kind() != reflect.Array && val.Kind() != reflect.Slice && val.Kind() != reflect.Map {
+		return &EncodeError{Type: val.Type(), Err: fmt.Errorf("%w: %v", ErrUnsupportedKind, val.Kind())}
 	}
 
-	if val.Kind() != reflect.Struct && val.Kind() != reflect.Array && val.Kind() != reflect.Slice && val.Kind() != reflect.Map {
-		return nil, fmt.Errorf("unexpects kind: %v", val.Kind())
+	return state.valueEncode(scope, val)
+}
+
+// encodeState 单次编码的状态
+type encodeState struct {
+	*QueryEncoder
+	values valueSink
+	// 当前路径上的指针、map、切片及其参数名，用于检测循环引用
+	visiting map[visitKey]string
+}
+
+// visitKey 引用的标识，切片需同时比较长度
+type visitKey struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// newVisitKey 返回引用的标识
+func newVisitKey(val reflect.Value) visitKey {
+	key := visitKey{ptr: val.Pointer(), typ: val.Type()}
+	if val.Kind() == reflect.Slice {
+		key.len = val.Len()
 	}
+	return key
+}
 
-	err := valueEncode(scope, values, val)
-	if err != nil {
-		return nil, err
+// enter 超过cycleCheckLevel层后记录当前路径上的引用，出现循环引用时返回包含循环路径的错误，
+// 返回true时需在离开该值时调用leave移除记录
+func (e *encodeState) enter(scope ScopeOptions, val reflect.Value) (bool, error) {
+	if scope.Level <= cycleCheckLevel {
+		return false, nil
+	}
+	key := newVisitKey(val)
+	if e.visiting == nil {
+		e.visiting = make(map[visitKey]string)
+	}
+	if first, ok := e.visiting[key]; ok {
+		return false, e.error(scope, val.Type(), fmt.Errorf("%w, first seen at %s", ErrCycle, scopeName(first)))
 	}
+	e.visiting[key] = scope.Scope
+	return true, nil
+}
 
-	return values, nil
+// leave 移除enter记录的引用
+func (e *encodeState) leave(val reflect.Value) {
+	delete(e.visiting, newVisitKey(val))
+}
+
+// scopeName 用于错误信息的参数名，顶层为空时使用<root>
+func scopeName(scope string) string {
+	if scope == "" {
+		return "<root>"
+	}
+	return scope
+}
+
+// error 返回当前参数的EncodeError，err已经是EncodeError时原样返回
+func (e *encodeState) error(scope ScopeOptions, typ reflect.Type, err error) error {
+	var ee *EncodeError
+	if errors.As(err, &ee) {
+		return err
+	}
+	return &EncodeError{Key: scope.Scope, Field: scope.path.String(), Type: typ, Err: err}
+}
+
+// sub 创建输出到values的编码状态，共享循环引用的记录，
+// 尚未记录引用时当前路径上没有需要共享的记录
+func (e *encodeState) sub(values valueSink) *encodeState {
+	return &encodeState{
+		QueryEncoder: e.QueryEncoder,
+		values:       values,
+		visiting:     e.visiting,
+	}
 }
 
 // valueEncode 	解析值
-func valueEncode(scope ScopeOptions, values url.Values, val reflect.Value) error {
-	if scope.Level > maxLevel {
-		return fmt.Errorf("recurse level too deep, the max is: %v", maxLevel)
+func (e *encodeState) valueEncode(scope ScopeOptions, val reflect.Value) error {
+	if e.opts.maxLevel > 0 && scope.Level > e.opts.maxLevel {
+		return e.error(scope, val.Type(), fmt.Errorf("%w, the max is: %v", ErrTooDeep, e.opts.maxLevel))
 	}
 	if val.Kind() == reflect.Ptr {
 		if val.IsNil() {
 			return nil
 		}
+		entered, err := e.enter(scope, val)
+		if err != nil {
+			return err
+		}
+		if entered {
+			defer e.leave(val)
+		}
 		val = val.Elem()
 	}
 
+	ti := cachedTypeInfo(val.Type(), e.opts.tagKey)
+
 	// 时间格式
-	if val.Type() == timeType {
+	if ti.isTime {
 		t := val.Interface().(time.Time)
-		values.Add(scope.Scope, t.Format(timeLayout))
+		e.values.add(scope.Scope, formatTime(t, e.opts.timeLayoutOf(scope)))
+		return nil
+	}
+
+	// 标准库类型
+	if ti.std != nil {
+		s, err := ti.std.format(scope, val)
+		if err != nil {
+			return e.error(scope, val.Type(), err)
+		}
+		e.values.add(scope.Scope, s)
 		return nil
 	}
 
 	// 自定义Encode方法
-	if val.Type().Implements(encoderType) {
+	if ti.isEncoder {
 		if !reflect.Indirect(val).IsValid() && val.Type().Elem().Implements(encoderType) {
 			val = reflect.New(val.Type().Elem())
 		}
 		m := val.Interface().(Encoder)
-		if err := m.EncodeValues(scope.Scope, &values); err != nil {
-			return err
+		if vs, ok := e.values.(valuesSink); ok {
+			uv := url.Values(vs)
+			if err := callEncoder(m, scope, &uv); err != nil {
+				return e.error(scope, val.Type(), err)
+			}
+			return nil
 		}
+		uv := make(url.Values)
+		if err := callEncoder(m, scope, &uv); err != nil {
+			return e.error(scope, val.Type(), err)
+		}
+		addSorted(e.values, uv)
 		return nil
 	}
+
+	// 多级指针中的nil指针及nil切片、map不输出，不调用MarshalText等方法
+	switch val.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map:
+		if val.IsNil() {
+			return nil
+		}
+	}
+
+	// 实现了encoding.TextMarshaler的类型，优先级低于Encoder
+	if m, ok := ti.textMarshalerOf(val); ok {
+		text, err := m.MarshalText()
+		if err != nil {
+			return e.error(scope, val.Type(), err)
+		}
+		e.values.add(scope.Scope, string(text))
+		return nil
+	}
+
+	// 实现了driver.Valuer的类型如sql.NullString，输出Value的结果，结果为nil时与nil指针相同不输出
+	if m, ok := ti.valuerOf(val); ok {
+		dv, err := m.Value()
+		if err != nil {
+			return e.error(scope, val.Type(), err)
+		}
+		if dv == nil {
+			return nil
+		}
+		return e.valueEncode(scope, reflect.ValueOf(dv))
+	}
+
+	// 实现了fmt.Stringer的类型，需通过WithStringer或标签选项string开启
+	if e.opts.useStringer(scope) {
+		if m, ok := ti.stringerOf(val); ok {
+			e.values.add(scope.Scope, m.String())
+			return nil
+		}
+	}
+
 	var err error
 	switch val.Kind() {
 	case reflect.Ptr:
-		err = valueEncode(scope, values, reflect.ValueOf(val.Interface()))
+		err = e.valueEncode(scope, reflect.ValueOf(val.Interface()))
 	case reflect.Struct:
-		err = structEncode(scope, values, val)
+		err = e.structEncode(scope, val)
 	case reflect.Slice, reflect.Array:
-		err = sliceEncode(scope, values, val)
+		err = e.sliceEncode(scope, val)
 	case reflect.Map:
-		err = mapEncode(scope, values, val)
+		err = e.mapEncode(scope, val)
 	case reflect.Interface:
-		err = valueEncode(scope, values, reflect.ValueOf(val.Interface()))
+		err = e.valueEncode(scope, reflect.ValueOf(val.Interface()))
+	case reflect.Float32, reflect.Float64:
+		err = e.floatEncode(scope, ti, val)
+	case reflect.Bool:
+		e.boolEncode(scope, ti, val)
 	default:
 		// 值全部使用fmt输出
-		values.Add(scope.Scope, fmt.Sprint(val.Interface()))
+		e.values.add(scope.Scope, fmt.Sprint(val.Interface()))
 	}
 	if err != nil {
 		return err
@@ -121,27 +319,48 @@ func valueEncode(scope ScopeOptions, values url.Values, val reflect.Value) error
 	return nil
 }
 
+// callEncoder 调用自定义的Encode方法，实现了levelEncoder时传入当前层数
+func callEncoder(m Encoder, scope ScopeOptions, uv *url.Values) error {
+	if le, ok := m.(levelEncoder); ok {
+		return le.EncodeValuesLevel(scope.Scope, uv, scope.Level)
+	}
+	return m.EncodeValues(scope.Scope, uv)
+}
+
 // mapEncode 解析map结构
-func mapEncode(scope ScopeOptions, values url.Values, val reflect.Value) error {
+func (e *encodeState) mapEncode(scope ScopeOptions, val reflect.Value) error {
 	if val.Len() == 0 {
 		return nil
 	}
+	entered, err := e.enter(scope, val)
+	if err != nil {
+		return err
+	}
+	if entered {
+		defer e.leave(val)
+	}
+	keys := make([]mapKey, 0, val.Len())
 	mapInte := val.MapRange()
 	for mapInte.Next() {
-		k := mapInte.Key()
-		if k.Kind() != reflect.String {
-			return fmt.Errorf("kind of map key must be string, get: %v", k.Kind())
-		}
-		key := k.String()
-		v := mapInte.Value()
-		newScope := scope.Scope + key
-		if scope.Scope != "" {
-			newScope = scope.Scope + "[" + key + "]"
-		}
-		err := valueEncode(ScopeOptions{
-			Scope: newScope,
-			Level: scope.Level + 1,
-		}, values, v)
+		name, err := mapKeyString(mapInte.Key())
+		if err != nil {
+			return e.error(scope, val.Type(), err)
+		}
+		keys = append(keys, mapKey{mapInte.Key(), name})
+	}
+	if e.values.ordered() {
+		e.opts.sortMapKeys(keys)
+	}
+	// 元素的路径引用path，复制后scope不必分配到堆上
+	path := scope.path
+	for _, k := range keys {
+		_, err := e.elemEncode(ScopeOptions{
+			Scope:    e.opts.joiner(scope.Scope, k.name, false),
+			Level:    scope.Level + 1,
+			format:   scope.format,
+			path:     path.key(k.name),
+			omitDeep: scope.omitDeep,
+		}, val.MapIndex(k.val))
 		if err != nil {
 			return err
 		}
@@ -149,49 +368,62 @@ func mapEncode(scope ScopeOptions, values url.Values, val reflect.Value) error {
 	return nil
 }
 
-// structEncode 解析结构体
-func structEncode(scope ScopeOptions, values url.Values, val reflect.Value) error {
-	typ := val.Type()
-	for i := 0; i < typ.NumField(); i++ {
-		sf := typ.Field(i)
-		// 私有字段
-		if sf.PkgPath != "" && !sf.Anonymous {
-			continue
+// mapKeyString 将map的key格式化为参数名，规则与encoding/json一致：
+// 字符串类型直接使用，其次使用encoding.TextMarshaler，整数、布尔值使用strconv格式化
+func mapKeyString(k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+	if m, ok := k.Interface().(encoding.TextMarshaler); ok {
+		if k.Kind() == reflect.Ptr && k.IsNil() {
+			return "", nil
 		}
-		sv := val.Field(i)
-		tag := sf.Tag.Get("qs")
-		// 忽略掉该字段
-		if tag == "-" {
-			continue
+		text, err := m.MarshalText()
+		if err != nil {
+			return "", err
 		}
+		return string(text), nil
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	case reflect.Bool:
+		return strconv.FormatBool(k.Bool()), nil
+	}
+	return "", fmt.Errorf("%w of map key: %v", ErrUnsupportedKind, k.Kind())
+}
+
+// structEncode 解析结构体
+func (e *encodeState) structEncode(scope ScopeOptions, val reflect.Value) error {
+	path := scope.path
+	for _, f := range cachedTypeInfo(val.Type(), e.opts.tagKey).fields {
+		sv := val.Field(f.index)
 		// 嵌套结构体
-		if sf.Anonymous {
+		if f.embedded {
 			v := reflect.Indirect(sv)
-			if v.IsValid() && v.Kind() == reflect.Struct {
-				err := valueEncode(scope, values, v)
-				if err != nil {
-					return err
-				}
+			if !v.IsValid() {
 				continue
 			}
+			err := e.valueEncode(scope, v)
+			if err != nil {
+				return err
+			}
+			continue
 		}
-		name, opts := parseTag(tag)
 		// 忽略零值对象
-		if opts.Contains("omitempty") && isEmptyValue(sv) {
+		if e.omitted(&f, sv) {
 			continue
 		}
-		if name == "" {
-			name = sf.Name
-		}
-		newScope := scope.Scope + name
-		if scope.Scope != "" {
-			newScope = scope.Scope + "[" + name + "]"
-		}
 		// 解析值
-		err := valueEncode(ScopeOptions{
-			Scope: newScope,
-			Level: scope.Level + 1,
-		}, values, sv)
+		_, err := e.elemEncode(ScopeOptions{
+			Scope:    e.opts.joiner(scope.Scope, f.name, false),
+			Level:    scope.Level + 1,
+			format:   f.format,
+			path:     path.field(f.fieldName),
+			omitDeep: f.omitDeep,
+		}, sv)
 		if err != nil {
 			return err
 		}
@@ -199,20 +431,164 @@ func structEncode(scope ScopeOptions, values url.Values, val reflect.Value) erro
 	return nil
 }
 
+// elemEncode 编码字段或元素，omitDeep时先编码到临时的输出，
+// 输出了参数时才写入，返回是否输出
+func (e *encodeState) elemEncode(scope ScopeOptions, val reflect.Value) (bool, error) {
+	if !scope.omitDeep {
+		return true, e.valueEncode(scope, val)
+	}
+	if isEmptyValue(val, e.opts.tagKey) {
+		return false, nil
+	}
+	var elems Ordered
+	if err := e.sub(&elems).valueEncode(scope, val); err != nil {
+		return false, err
+	}
+	if len(elems) == 0 {
+		return false, nil
+	}
+	for _, p := range elems {
+		e.values.add(p.Key, p.Value)
+	}
+	return true, nil
+}
+
+// fieldPath Go字段路径，保存父路径及最后一段，出错时才拼接为字符串，零值为顶层
+type fieldPath struct {
+	parent *fieldPath
+	kind   pathKind
+	name   string
+	i      int
+}
+
+// pathKind 路径最后一段的类型
+type pathKind uint8
+
+const (
+	pathRoot pathKind = iota
+	pathField
+	pathKey
+	pathIndex
+)
+
+// field 返回字段name的路径
+func (p *fieldPath) field(name string) fieldPath {
+	return fieldPath{parent: p, kind: pathField, name: name}
+}
+
+// key 返回map元素的路径
+func (p *fieldPath) key(name string) fieldPath {
+	return fieldPath{parent: p, kind: pathKey, name: name}
+}
+
+// index 返回数组、切片元素的路径
+func (p *fieldPath) index(i int) fieldPath {
+	return fieldPath{parent: p, kind: pathIndex, i: i}
+}
+
+// String 拼接路径，如A.B["k"][0]
+func (p *fieldPath) String() string {
+	if p == nil || p.kind == pathRoot {
+		return ""
+	}
+	parent := p.parent.String()
+	switch p.kind {
+	case pathField:
+		if parent == "" {
+			return p.name
+		}
+		return parent + "." + p.name
+	case pathKey:
+		return parent + "[" + strconv.Quote(p.name) + "]"
+	default:
+		return parent + "[" + strconv.Itoa(p.i) + "]"
+	}
+}
+
 // 解析数组、切片的值
-func sliceEncode(scope ScopeOptions, values url.Values, val reflect.Value) error {
+func (e *encodeState) sliceEncode(scope ScopeOptions, val reflect.Value) error {
 	// 跳过空slice
 	if val.Len() == 0 {
 		return nil
 	}
+	if val.Kind() == reflect.Slice {
+		entered, err := e.enter(scope, val)
+		if err != nil {
+			return err
+		}
+		if entered {
+			defer e.leave(val)
+		}
+	}
+	if f := e.opts.bytesFormatOf(scope, val.Type()); f != BytesElements {
+		e.values.add(scope.Scope, f.encode(bytesOf(val)))
+		return nil
+	}
+	format, delimiter := e.opts.arrayStyle(scope)
+	if delimiter != "" {
+		return e.delimitedEncode(scope, val, delimiter)
+	}
+	// 忽略的元素不占用下标
+	n := 0
+	path := scope.path
 	for i := 0; i < val.Len(); i++ {
-		err := valueEncode(ScopeOptions{
-			Scope: scope.Scope + "[" + strconv.Itoa(i) + "]",
-			Level: scope.Level + 1,
-		}, values, val.Index(i))
+		var elemScope string
+		switch format {
+		case ArrayBrackets:
+			elemScope = scope.Scope + "[]"
+		case ArrayRepeat:
+			elemScope = scope.Scope
+		default:
+			elemScope = e.opts.joiner(scope.Scope, strconv.Itoa(n), true)
+		}
+		ok, err := e.elemEncode(ScopeOptions{
+			Scope:    elemScope,
+			Level:    scope.Level + 1,
+			format:   scope.format,
+			path:     path.index(i),
+			omitDeep: scope.omitDeep,
+		}, val.Index(i))
 		if err != nil {
 			return err
 		}
+		if ok {
+			n++
+		}
+	}
+	return nil
+}
+
+// delimitedEncode 将数组、切片的元素使用分隔符拼接为一个值
+func (e *encodeState) delimitedEncode(scope ScopeOptions, val reflect.Value, delimiter string) error {
+	var elems Ordered
+	sub := e.sub(&elems)
+	path := scope.path
+	for i := 0; i < val.Len(); i++ {
+		_, err := sub.elemEncode(ScopeOptions{
+			Scope:    scope.Scope,
+			Level:    scope.Level + 1,
+			format:   scope.format,
+			path:     path.index(i),
+			omitDeep: scope.omitDeep,
+		}, val.Index(i))
+		if err != nil {
+			return err
+		}
+	}
+	var parts []string
+	for _, p := range elems {
+		if p.Key == scope.Scope {
+			parts = append(parts, p.Value)
+		}
+	}
+	if len(parts) > 0 {
+		e.values.add(scope.Scope, strings.Join(parts, delimiter))
+	}
+	// 元素为结构体等类型时产生的其他参数原样保留
+	for _, p := range elems {
+		if p.Key != scope.Scope {
+			e.values.add(p.Key, p.Value)
+		}
 	}
 	return nil
 }
@@ -238,9 +614,62 @@ func (o tagOptions) Contains(option string) bool {
 	return false
 }
 
+// omitted 字段是否按忽略选项跳过，字段未指定忽略选项时使用编码器的默认策略
+func (e *encodeState) omitted(f *fieldInfo, v reflect.Value) bool {
+	empty, zero, null := f.omitEmpty, f.omitZero, f.omitNil
+	if !empty && !zero && !null {
+		switch e.opts.omitPolicy {
+		case OmitEmpty:
+			empty = true
+		case OmitZero:
+			zero = true
+		case OmitNil:
+			null = true
+		}
+	}
+	return (empty && isEmptyValue(v, e.opts.tagKey)) || (zero && isZeroValue(v)) || (null && isNilValue(v, e.opts.tagKey))
+}
+
+// isZeroValue 值或其指针实现了IsZero时使用IsZero的结果，否则判断是否为类型的零值，
+// nil指针、接口为零值
+func isZeroValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return true
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			return true
+		}
+		return isZeroValue(v.Elem())
+	}
+	if z, ok := v.Interface().(zeroable); ok {
+		return z.IsZero()
+	}
+	if reflect.PtrTo(v.Type()).Implements(zeroableType) {
+		if !v.CanAddr() {
+			p := reflect.New(v.Type())
+			p.Elem().Set(v)
+			v = p.Elem()
+		}
+		return v.Addr().Interface().(zeroable).IsZero()
+	}
+	return v.IsZero()
+}
+
+// isNilValue 是否为nil的指针、接口、切片、map、函数、通道，或Value返回nil的driver.Valuer
+func isNilValue(v reflect.Value, tagKey string) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map, reflect.Func, reflect.Chan:
+		return v.IsNil()
+	}
+	return isNullValuer(v, tagKey)
+}
+
 // isEmptyValue checks if a value should be considered empty for the purposes
 // of omitting fields with the "omitempty" option.
-func isEmptyValue(v reflect.Value) bool {
+func isEmptyValue(v reflect.Value, tagKey string) bool {
 	switch v.Kind() {
 	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
 		return v.Len() == 0
@@ -255,6 +684,9 @@ func isEmptyValue(v reflect.Value) bool {
 	case reflect.Interface, reflect.Ptr:
 		return v.IsNil()
 	}
+	if isNullValuer(v, tagKey) {
+		return true
+	}
 	if z, ok := v.Interface().(zeroable); ok {
 		return z.IsZero()
 	}
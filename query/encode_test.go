@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"net/url"
 	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -353,6 +355,186 @@ func TestValues_OmitEmpty(t *testing.T) {
 	}
 }
 
+// ptrZero 通过指针接收者实现IsZero
+type ptrZero struct {
+	N int
+}
+
+func (z *ptrZero) IsZero() bool {
+	return z.N <= 0
+}
+
+func TestValues_OmitZeroNil(t *testing.T) {
+	zero, str := 0, ""
+	type Point struct {
+		X, Y int
+	}
+
+	tests := []struct {
+		input interface{}
+		want  url.Values
+	}{
+		{
+			struct {
+				A int       `qs:"a,omitzero"`
+				B bool      `qs:"b,omitzero"`
+				P Point     `qs:"p,omitzero"`
+				T time.Time `qs:"t,omitzero"`
+				Z ptrZero   `qs:"z,omitzero"`
+				S []int     `qs:"s,omitzero"`
+				I *int      `qs:"i,omitzero"`
+			}{Z: ptrZero{-1}},
+			url.Values{},
+		},
+		{
+			struct {
+				P Point   `qs:"p,omitzero"`
+				Z ptrZero `qs:"z,omitzero"`
+				I *int    `qs:"i,omitzero"`
+			}{P: Point{X: 1}, Z: ptrZero{1}, I: &zero},
+			url.Values{"p[X]": {"1"}, "p[Y]": {"0"}, "z[N]": {"1"}, "i": {"0"}},
+		},
+		{
+			// omitempty不会忽略结构体
+			struct {
+				P Point `qs:"p,omitempty"`
+			}{},
+			url.Values{"p[X]": {"0"}, "p[Y]": {"0"}},
+		},
+		{
+			struct {
+				A int               `qs:"a,omitnil"`
+				B bool              `qs:"b,omitnil"`
+				S *string           `qs:"s,omitnil"`
+				I interface{}       `qs:"i,omitnil"`
+				M map[string]string `qs:"m,omitnil"`
+				L []int             `qs:"l,omitnil"`
+			}{S: &str},
+			url.Values{"a": {"0"}, "b": {"false"}, "s": {""}},
+		},
+	}
+
+	for _, tt := range tests {
+		testValue(t, tt.input, tt.want)
+	}
+}
+
+func TestValues_OmitEmptyDeep(t *testing.T) {
+	type Filter struct {
+		Name string `qs:"name,omitempty"`
+		Tags []int  `qs:"tags,omitempty"`
+	}
+	type Item struct {
+		Name string `qs:"name"`
+	}
+
+	tests := []struct {
+		input interface{}
+		want  url.Values
+	}{
+		{
+			struct {
+				A Filter `qs:"a,omitempty"`
+				B Filter `qs:"b,omitempty=deep"`
+				C Item   `qs:"c,omitempty=deep"`
+				D *Item  `qs:"d,omitempty=deep"`
+				E string `qs:"e,omitempty=deep"`
+			}{D: &Item{}},
+			// Item的name没有omitempty，编码结果包含参数，不会被忽略
+			url.Values{"c[name]": {""}, "d[name]": {""}},
+		},
+		{
+			struct {
+				B Filter `qs:"b,omitempty=deep"`
+				C Item   `qs:"c,omitempty=deep"`
+			}{Filter{Tags: []int{0}}, Item{"x"}},
+			url.Values{"b[tags][0]": {"0"}, "c[name]": {"x"}},
+		},
+		{
+			// 没有输出参数的元素不输出，下标依次排列
+			struct {
+				Items []Filter `qs:"items,omitempty=deep"`
+				Ptrs  []*Item  `qs:"ptrs,omitempty=deep"`
+				Names []string `qs:"names,comma,omitempty=deep"`
+				Tags  []string `qs:"tags,repeat,omitempty=deep"`
+			}{
+				[]Filter{{}, {Name: "a"}, {}, {Name: "b"}},
+				[]*Item{nil, {"c"}},
+				[]string{"", "x", "", "y"},
+				[]string{"", "z"},
+			},
+			url.Values{
+				"items[0][name]": {"a"},
+				"items[1][name]": {"b"},
+				"ptrs[0][name]":  {"c"},
+				"names":          {"x,y"},
+				"tags":           {"z"},
+			},
+		},
+		{
+			struct {
+				Items []Item            `qs:"items,omitempty=deep"`
+				M     map[string]Item   `qs:"m,omitempty=deep"`
+				F     []Filter          `qs:"f,omitempty=deep"`
+				N     map[string]Filter `qs:"n,omitempty=deep"`
+			}{
+				[]Item{{}},
+				map[string]Item{"a": {}, "b": {"x"}},
+				[]Filter{{}},
+				map[string]Filter{"a": {}, "b": {Name: "x"}},
+			},
+			url.Values{"items[0][name]": {""}, "m[a][name]": {""}, "m[b][name]": {"x"}, "n[b][name]": {"x"}},
+		},
+		{
+			// 没有omitempty=deep时保持原有行为
+			struct {
+				Items []Item `qs:"items,omitempty"`
+			}{[]Item{{}, {"a"}}},
+			url.Values{"items[0][name]": {""}, "items[1][name]": {"a"}},
+		},
+	}
+
+	for _, tt := range tests {
+		testValue(t, tt.input, tt.want)
+	}
+}
+
+func TestEncoder_OmitPolicy(t *testing.T) {
+	type Point struct {
+		X int `qs:"x"`
+	}
+	type Patch struct {
+		Name  *string `qs:"name"`
+		Count int     `qs:"count"`
+		Point Point   `qs:"point"`
+		Tag   string  `qs:"tag,omitnil"`
+	}
+	name := "n"
+	input := Patch{Name: &name}
+
+	tests := []struct {
+		policy OmitPolicy
+		want   url.Values
+	}{
+		{OmitNone, url.Values{"name": {"n"}, "count": {"0"}, "point[x]": {"0"}, "tag": {""}}},
+		// 默认策略同样作用于嵌套结构体的字段
+		{OmitEmpty, url.Values{"name": {"n"}, "tag": {""}}},
+		{OmitZero, url.Values{"name": {"n"}, "tag": {""}}},
+		{OmitNil, url.Values{"name": {"n"}, "count": {"0"}, "point[x]": {"0"}, "tag": {""}}},
+	}
+
+	for _, tt := range tests {
+		got, err := NewEncoder(WithOmitPolicy(tt.policy)).Values(input)
+		if err != nil {
+			t.Errorf("Values(%v) with policy %v returned error: %v", input, tt.policy, err)
+			continue
+		}
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("Values(%v) with policy %v mismatch:\n%s", input, tt.policy, diff)
+		}
+	}
+}
+
 func TestValues_EmbeddedStructs(t *testing.T) {
 	type Inner struct {
 		V string
@@ -695,7 +877,7 @@ func TestIsEmptyValue(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		got := isEmptyValue(reflect.ValueOf(tt.value))
+		got := isEmptyValue(reflect.ValueOf(tt.value), defaultTagKey)
 		want := tt.empty
 		if got != want {
 			t.Errorf("isEmptyValue(%v) returned %t; want %t", tt.value, got, want)
@@ -722,3 +904,264 @@ func TestParseTag(t *testing.T) {
 		}
 	}
 }
+
+// textEnum is an int that marshals itself as text
+type textEnum int
+
+var textEnumNames = []string{"zero", "one", "two"}
+
+func (m textEnum) MarshalText() ([]byte, error) {
+	if int(m) >= len(textEnumNames) {
+		return nil, errors.New("unknown enum")
+	}
+	return []byte(textEnumNames[m]), nil
+}
+
+func (m *textEnum) UnmarshalText(text []byte) error {
+	for i, name := range textEnumNames {
+		if name == string(text) {
+			*m = textEnum(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown enum %q", text)
+}
+
+// textList is a slice that marshals itself as text
+type textList []string
+
+func (m textList) MarshalText() ([]byte, error) {
+	return []byte(strings.Join(m, "|")), nil
+}
+
+// textStruct is a struct that marshals itself as text through a pointer
+// receiver.
+type textStruct struct {
+	A, B string
+}
+
+func (m *textStruct) MarshalText() ([]byte, error) {
+	return []byte(m.A + "-" + m.B), nil
+}
+
+// textEncoded implements both Encoder and encoding.TextMarshaler.
+type textEncoded string
+
+func (m textEncoded) MarshalText() ([]byte, error) {
+	return []byte("text"), nil
+}
+
+func (m textEncoded) EncodeValues(key string, v *url.Values) error {
+	v.Set(key, "encoder")
+	return nil
+}
+
+// stringerPoint is a struct with a String method
+type stringerPoint struct {
+	X, Y int
+}
+
+func (m stringerPoint) String() string {
+	return fmt.Sprintf("%d:%d", m.X, m.Y)
+}
+
+func TestValues_TextMarshaler(t *testing.T) {
+	two := textEnum(2)
+	tests := []struct {
+		input interface{}
+		want  url.Values
+	}{
+		{struct{ V textEnum }{1}, url.Values{"V": {"one"}}},
+		{struct{ V *textEnum }{&two}, url.Values{"V": {"two"}}},
+		{struct{ V *textEnum }{}, url.Values{}},
+		{struct{ V **textEnum }{new(*textEnum)}, url.Values{}},
+		{struct{ V textList }{}, url.Values{}},
+		{struct{ V textList }{textList{}}, url.Values{"V": {""}}},
+		{struct{ V textList }{textList{"a", "b"}}, url.Values{"V": {"a|b"}}},
+		{
+			struct {
+				V **stringerPoint `qs:",string"`
+				M **money
+			}{new(*stringerPoint), new(*money)},
+			url.Values{},
+		},
+		{struct{ V []textEnum }{[]textEnum{0, 1}}, url.Values{"V[0]": {"zero"}, "V[1]": {"one"}}},
+		{struct{ V map[string]textEnum }{map[string]textEnum{"a": 2}}, url.Values{"V[a]": {"two"}}},
+
+		// pointer receivers are used for addressable values only
+		{struct{ V *textStruct }{&textStruct{"a", "b"}}, url.Values{"V": {"a-b"}}},
+		{&struct{ V textStruct }{textStruct{"a", "b"}}, url.Values{"V": {"a-b"}}},
+		{struct{ V textStruct }{textStruct{"a", "b"}}, url.Values{"V[A]": {"a"}, "V[B]": {"b"}}},
+
+		// Encoder takes precedence over encoding.TextMarshaler
+		{struct{ V textEncoded }{"v"}, url.Values{"V": {"encoder"}}},
+
+		// fmt.Stringer is not used unless enabled
+		{struct{ V stringerPoint }{stringerPoint{1, 2}}, url.Values{"V[X]": {"1"}, "V[Y]": {"2"}}},
+		{
+			struct {
+				V stringerPoint `qs:",string"`
+			}{stringerPoint{1, 2}},
+			url.Values{"V": {"1:2"}},
+		},
+		{
+			struct {
+				V []stringerPoint `qs:",string"`
+			}{[]stringerPoint{{1, 2}, {3, 4}}},
+			url.Values{"V[0]": {"1:2"}, "V[1]": {"3:4"}},
+		},
+	}
+
+	for _, tt := range tests {
+		testValue(t, tt.input, tt.want)
+	}
+
+	testEncoderValue(t, NewEncoder(WithStringer()), struct {
+		V stringerPoint
+		E textEnum
+	}{stringerPoint{1, 2}, 1}, url.Values{"V": {"1:2"}, "E": {"one"}})
+
+	if _, err := Values(struct{ V textEnum }{9}); err == nil {
+		t.Errorf("expected Values() to return the MarshalText error")
+	}
+}
+
+type namedKey string
+
+func TestValues_MapKeys(t *testing.T) {
+	tests := []struct {
+		input interface{}
+		want  url.Values
+	}{
+		{map[namedKey]int{"a": 1}, url.Values{"a": {"1"}}},
+		{map[int]string{-1: "a", 2: "b"}, url.Values{"-1": {"a"}, "2": {"b"}}},
+		{map[uint8]string{1: "a"}, url.Values{"1": {"a"}}},
+		{map[bool]int{true: 1, false: 0}, url.Values{"true": {"1"}, "false": {"0"}}},
+		{map[textEnum]bool{1: true}, url.Values{"one": {"true"}}},
+		{
+			struct {
+				M map[int]string `qs:"m"`
+			}{map[int]string{1: "a"}},
+			url.Values{"m[1]": {"a"}},
+		},
+	}
+
+	for _, tt := range tests {
+		testValue(t, tt.input, tt.want)
+	}
+
+	for _, input := range []interface{}{
+		map[float64]string{1: "a"},
+		map[textEnum]bool{9: true},
+	} {
+		if _, err := Values(input); err == nil {
+			t.Errorf("Values(%v) did not return expected error", input)
+		}
+	}
+}
+
+type cycleNode struct {
+	Name string     `qs:"name"`
+	Next *cycleNode `qs:"next,omitempty"`
+}
+
+func TestValues_Cycle(t *testing.T) {
+	a := &cycleNode{Name: "a"}
+	b := &cycleNode{Name: "b", Next: a}
+	a.Next = b
+
+	m := map[string]interface{}{"k": 1}
+	m["self"] = m
+
+	s := []interface{}{1, nil}
+	s[1] = s
+
+	// 超过cycleCheckLevel层后才开始记录引用
+	enc := NewEncoder(WithMaxDepth(UnlimitedDepth))
+	next := func(n int) string { return "next" + strings.Repeat("[next]", n-1) }
+	tests := []struct {
+		input interface{}
+		want  string
+	}{
+		{a, `query: encode ` + next(9) + ` (field Next` + strings.Repeat(".Next", 8) + `) of type *query.cycleNode: encountered a cycle, first seen at ` + next(7)},
+		{*a, `query: encode ` + next(9) + ` (field Next` + strings.Repeat(".Next", 8) + `) of type *query.cycleNode: encountered a cycle, first seen at ` + next(7)},
+		{m, `query: encode self` + strings.Repeat("[self]", 7) + ` (field ` + strings.Repeat(`["self"]`, 8) + `) of type map[string]interface {}: encountered a cycle, first seen at self` + strings.Repeat("[self]", 6)},
+		{struct{ S []interface{} }{s}, `query: encode S` + strings.Repeat("[1]", 7) + ` (field S` + strings.Repeat("[1]", 7) + `) of type []interface {}: encountered a cycle, first seen at S` + strings.Repeat("[1]", 6)},
+	}
+	for _, tt := range tests {
+		_, err := enc.Values(tt.input)
+		if err == nil {
+			t.Errorf("Values(%T) did not return expected cycle error", tt.input)
+			continue
+		}
+		if !errors.Is(err, ErrCycle) {
+			t.Errorf("Values(%T) returned error %v, want ErrCycle", tt.input, err)
+		}
+		if err.Error() != tt.want {
+			t.Errorf("Values(%T) returned error %q, want %q", tt.input, err, tt.want)
+		}
+	}
+
+	// the default depth limit still applies
+	if _, err := Values(a); err == nil {
+		t.Errorf("Values(cycle) did not return expected error")
+	}
+}
+
+// levelEncoded records the nesting level it is encoded at
+type levelEncoded struct{}
+
+func (levelEncoded) EncodeValues(key string, v *url.Values) error {
+	return levelEncoded{}.EncodeValuesLevel(key, v, 1)
+}
+
+func (levelEncoded) EncodeValuesLevel(key string, v *url.Values, level int) error {
+	v.Set(key, strconv.Itoa(level))
+	return nil
+}
+
+func TestValues_LevelEncoder(t *testing.T) {
+	input := struct {
+		A levelEncoded            `qs:"a"`
+		L []levelEncoded          `qs:"l"`
+		M map[string]levelEncoded `qs:"m"`
+	}{L: []levelEncoded{{}}, M: map[string]levelEncoded{"k": {}}}
+
+	testValue(t, input, url.Values{"a": {"2"}, "l[0]": {"3"}, "m[k]": {"3"}})
+}
+
+func TestValues_SharedReferences(t *testing.T) {
+	shared := &cycleNode{Name: "s"}
+	ids := []int{1}
+	input := struct {
+		A *cycleNode `qs:"a"`
+		B *cycleNode `qs:"b"`
+		C []int      `qs:"c"`
+		D []int      `qs:"d"`
+	}{shared, shared, ids, ids}
+
+	testValue(t, input, url.Values{
+		"a[name]": {"s"},
+		"b[name]": {"s"},
+		"c[0]":    {"1"},
+		"d[0]":    {"1"},
+	})
+}
+
+func TestValues_UnlimitedDepth(t *testing.T) {
+	var head *cycleNode
+	for i := 9; i >= 0; i-- {
+		head = &cycleNode{Name: fmt.Sprint(i), Next: head}
+	}
+	if _, err := Values(head); err == nil {
+		t.Errorf("expected Values() to return an error when exceeding the default depth")
+	}
+	v, err := NewEncoder(WithMaxDepth(UnlimitedDepth)).Values(head)
+	if err != nil {
+		t.Fatalf("Values returned error: %v", err)
+	}
+	key := "next[next][next][next][next][next][next][next][next][name]"
+	if got := v.Get(key); got != "9" {
+		t.Errorf("Values()[%q] = %q, want %q", key, got, "9")
+	}
+}
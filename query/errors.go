@@ -0,0 +1,49 @@
+package query
+
+import (
+	"errors"
+	"reflect"
+)
+
+var (
+	// ErrUnsupportedKind 不支持编码的类型
+	ErrUnsupportedKind = errors.New("unsupported kind")
+	// ErrTooDeep 超过最大嵌套层数
+	ErrTooDeep = errors.New("recurse level too deep")
+	// ErrCycle 存在循环引用
+	ErrCycle = errors.New("encountered a cycle")
+	// ErrNonFinite 浮点数为NaN或正负无穷，在WithNonFinite(NonFiniteError)时返回
+	ErrNonFinite = errors.New("non-finite float")
+)
+
+// EncodeError 编码错误，包含出错的参数名、字段路径、类型及原始错误，
+// 可通过errors.Is、errors.As判断原始错误
+type EncodeError struct {
+	// Key 参数名，如 nest[a][value]
+	Key string
+	// Field Go字段路径，如 Nest.A.Value，匿名嵌入的结构体名称省略
+	Field string
+	// Type 出错的值的类型
+	Type reflect.Type
+	// Err 原始错误
+	Err error
+}
+
+func (e *EncodeError) Error() string {
+	msg := "query: encode"
+	if e.Key != "" {
+		msg += " " + e.Key
+	}
+	if e.Field != "" {
+		msg += " (field " + e.Field + ")"
+	}
+	if e.Type != nil {
+		msg += " of type " + e.Type.String()
+	}
+	return msg + ": " + e.Err.Error()
+}
+
+// Unwrap 返回原始错误
+func (e *EncodeError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,103 @@
+package query
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+var errSentinel = errors.New("sentinel")
+
+type failingEncoder struct{}
+
+func (failingEncoder) MarshalText() ([]byte, error) {
+	return nil, errSentinel
+}
+
+func TestEncodeError(t *testing.T) {
+	type Leaf struct {
+		Value failingEncoder `qs:"value"`
+	}
+	type Embedded struct {
+		A Leaf `qs:"a"`
+	}
+
+	tests := []struct {
+		input interface{}
+		want  EncodeError
+		is    error
+	}{
+		{
+			struct {
+				Nest struct{ A Leaf } `qs:"nest"`
+			}{},
+			EncodeError{Key: "nest[A][value]", Field: "Nest.A.Value", Type: reflect.TypeOf(failingEncoder{}), Err: errSentinel},
+			errSentinel,
+		},
+		{
+			struct {
+				Embedded
+			}{},
+			EncodeError{Key: "a[value]", Field: "A.Value", Type: reflect.TypeOf(failingEncoder{}), Err: errSentinel},
+			errSentinel,
+		},
+		{
+			struct {
+				Items []map[string]failingEncoder `qs:"items"`
+			}{[]map[string]failingEncoder{{"k": {}}}},
+			EncodeError{Key: "items[0][k]", Field: `Items[0]["k"]`, Type: reflect.TypeOf(failingEncoder{}), Err: errSentinel},
+			errSentinel,
+		},
+		{
+			struct {
+				V customEncodedStrings `qs:"v"`
+			}{customEncodedStrings{"err"}},
+			EncodeError{Key: "v", Field: "V", Type: reflect.TypeOf(customEncodedStrings{})},
+			nil,
+		},
+		{
+			struct {
+				M map[float64]int `qs:"m"`
+			}{map[float64]int{1: 1}},
+			EncodeError{Key: "m", Field: "M", Type: reflect.TypeOf(map[float64]int{})},
+			ErrUnsupportedKind,
+		},
+		{
+			"string",
+			EncodeError{Type: reflect.TypeOf("")},
+			ErrUnsupportedKind,
+		},
+	}
+
+	for _, tt := range tests {
+		_, err := Values(tt.input)
+		var got *EncodeError
+		if !errors.As(err, &got) {
+			t.Errorf("Values(%#v) returned error %v, want *EncodeError", tt.input, err)
+			continue
+		}
+		if got.Key != tt.want.Key || got.Field != tt.want.Field || got.Type != tt.want.Type {
+			t.Errorf("Values(%#v) returned error {Key: %q, Field: %q, Type: %v}, want {Key: %q, Field: %q, Type: %v}",
+				tt.input, got.Key, got.Field, got.Type, tt.want.Key, tt.want.Field, tt.want.Type)
+		}
+		if tt.is != nil && !errors.Is(err, tt.is) {
+			t.Errorf("Values(%#v) returned error %v, want errors.Is %v", tt.input, err, tt.is)
+		}
+	}
+}
+
+func TestEncodeError_Depth(t *testing.T) {
+	type Level struct {
+		Next *Level `qs:"next"`
+	}
+	input := &Level{&Level{&Level{}}}
+
+	_, err := NewEncoder(WithMaxDepth(2)).Values(input)
+	if !errors.Is(err, ErrTooDeep) {
+		t.Fatalf("Values returned error %v, want ErrTooDeep", err)
+	}
+	want := "query: encode next[next] (field Next.Next) of type *query.Level: recurse level too deep, the max is: 2"
+	if err.Error() != want {
+		t.Errorf("Values returned error %q, want %q", err, want)
+	}
+}
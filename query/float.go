@@ -0,0 +1,130 @@
+package query
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// NonFinitePolicy NaN、正负无穷的编码方式
+type NonFinitePolicy int
+
+const (
+	// NonFiniteLiteral 输出 NaN、+Inf、-Inf，默认方式
+	NonFiniteLiteral NonFinitePolicy = iota
+	// NonFiniteError 返回ErrNonFinite
+	NonFiniteError
+	// NonFiniteSkip 不输出该值
+	NonFiniteSkip
+)
+
+// floatStyle 浮点数的格式，零值表示未指定，与fmt.Sprint的输出一致
+type floatStyle struct {
+	// strconv.FormatFloat的格式，如 'f'、'e'、'g'
+	format  byte
+	prec    int
+	hasPrec bool
+	// 结果中总是包含小数点，如 1 输出为 1.0
+	decimal bool
+}
+
+// isSet 是否指定了格式
+func (s floatStyle) isSet() bool {
+	return s.format != 0 || s.hasPrec || s.decimal
+}
+
+// parseFloatOption 解析标签中浮点数的格式选项：prec=N、fixed、decimal、fmt=f，不是浮点数选项时返回false
+func (s *floatStyle) parseFloatOption(opt string) bool {
+	switch {
+	case strings.HasPrefix(opt, "prec="):
+		n, err := strconv.Atoi(strings.TrimPrefix(opt, "prec="))
+		if err != nil || n < 0 {
+			return false
+		}
+		s.prec, s.hasPrec = n, true
+		// 指定精度时默认不使用指数形式
+		if s.format == 0 {
+			s.format = 'f'
+		}
+	case opt == "fixed":
+		s.format = 'f'
+	case opt == "decimal":
+		s.decimal = true
+	case strings.HasPrefix(opt, "fmt=") && len(opt) == len("fmt=")+1:
+		s.format = opt[len(opt)-1]
+	default:
+		return false
+	}
+	return true
+}
+
+// floatStyleOf 返回当前域使用的浮点数格式，字段的选项优先于全局配置
+func (o *options) floatStyleOf(scope ScopeOptions) floatStyle {
+	s := o.floatStyle
+	if scope.format == nil {
+		return s
+	}
+	f := scope.format.float
+	if f.format != 0 {
+		s.format = f.format
+	}
+	if f.hasPrec {
+		s.prec, s.hasPrec = f.prec, true
+	}
+	if f.decimal {
+		s.decimal = true
+	}
+	return s
+}
+
+// floatEncode 按格式及NaN、无穷的处理方式输出浮点数，没有指定格式时与fmt.Sprint的输出一致
+func (e *encodeState) floatEncode(scope ScopeOptions, ti *typeInfo, val reflect.Value) error {
+	f := val.Float()
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		switch e.opts.nonFinite {
+		case NonFiniteError:
+			return e.error(scope, val.Type(), fmt.Errorf("%w: %v", ErrNonFinite, f))
+		case NonFiniteSkip:
+			return nil
+		}
+	}
+	style := e.opts.floatStyleOf(scope)
+	// 实现了fmt.Stringer等方法的类型由fmt输出
+	if !style.isSet() || ti.stringer || ti.formatter {
+		e.values.add(scope.Scope, fmt.Sprint(val.Interface()))
+		return nil
+	}
+	s, err := style.formatFloat(f, val.Type().Bits())
+	if err != nil {
+		return e.error(scope, val.Type(), err)
+	}
+	e.values.add(scope.Scope, s)
+	return nil
+}
+
+var formatterType = reflect.TypeOf(new(fmt.Formatter)).Elem()
+
+// formatFloat 使用strconv.FormatFloat格式化，未指定格式时为'g'，未指定精度时为最短表示
+func (s floatStyle) formatFloat(f float64, bitSize int) (string, error) {
+	format, prec := s.format, -1
+	if format == 0 {
+		format = 'g'
+	}
+	if s.hasPrec {
+		prec = s.prec
+	}
+	if !strings.ContainsRune("beEfgGxX", rune(format)) {
+		return "", fmt.Errorf("invalid float format %q", format)
+	}
+	str := strconv.FormatFloat(f, format, prec, bitSize)
+	if s.decimal && format != 'b' && !math.IsNaN(f) && !math.IsInf(f, 0) && !strings.ContainsRune(str, '.') {
+		if i := strings.IndexAny(str, "eEpP"); i >= 0 {
+			str = str[:i] + ".0" + str[i:]
+		} else {
+			str += ".0"
+		}
+	}
+	return str, nil
+}
@@ -0,0 +1,107 @@
+package query
+
+import (
+	"errors"
+	"math"
+	"net/url"
+	"testing"
+)
+
+// celsius 实现了fmt.Stringer的浮点数
+type celsius float64
+
+func (c celsius) String() string {
+	return "warm"
+}
+
+func TestValues_FloatFormat(t *testing.T) {
+	tests := []struct {
+		input interface{}
+		want  url.Values
+	}{
+		{
+			// 默认与fmt.Sprint一致
+			struct {
+				A float64
+				B float32
+				C float64
+			}{1e21, 0.1, 2},
+			url.Values{"A": {"1e+21"}, "B": {"0.1"}, "C": {"2"}},
+		},
+		{
+			struct {
+				Price float64   `qs:"price,prec=2"`
+				Big   float64   `qs:"big,fixed"`
+				Small float32   `qs:"small,fixed"`
+				Whole float64   `qs:"whole,decimal"`
+				Exp   float64   `qs:"exp,fmt=e,prec=3"`
+				Dec   float64   `qs:"dec,decimal,fmt=e"`
+				List  []float64 `qs:"list,comma,prec=1"`
+				Temp  celsius   `qs:"temp,prec=1"`
+			}{1.005, 1e21, 0.1, 3, 1234.5678, 1e21, []float64{1, 2.25}, 20},
+			url.Values{
+				"price": {"1.00"},
+				"big":   {"1000000000000000000000"},
+				"small": {"0.1"},
+				"whole": {"3.0"},
+				"exp":   {"1.235e+03"},
+				"dec":   {"1.0e+21"},
+				"list":  {"1.0,2.2"},
+				"temp":  {"warm"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		testValue(t, tt.input, tt.want)
+	}
+}
+
+func TestEncoder_FloatOptions(t *testing.T) {
+	input := struct {
+		A float64
+		B float64 `qs:"B,prec=1"`
+		C float64
+	}{1e21, 2, math.Inf(-1)}
+
+	testEncoderValue(t, NewEncoder(WithFloatFormat('f', -1), WithFloatDecimal()), input,
+		url.Values{"A": {"1000000000000000000000.0"}, "B": {"2.0"}, "C": {"-Inf"}})
+	testEncoderValue(t, NewEncoder(WithFloatFormat('f', 3)), input,
+		url.Values{"A": {"1000000000000000000000.000"}, "B": {"2.0"}, "C": {"-Inf"}})
+	testEncoderValue(t, NewEncoder(WithNonFinite(NonFiniteSkip)), input,
+		url.Values{"A": {"1e+21"}, "B": {"2.0"}})
+
+	_, err := NewEncoder(WithNonFinite(NonFiniteError)).Values(input)
+	var ee *EncodeError
+	if !errors.Is(err, ErrNonFinite) || !errors.As(err, &ee) || ee.Key != "C" {
+		t.Errorf("Values with NonFiniteError returned error %v, want %v for C", err, ErrNonFinite)
+	}
+
+	_, err = Values(struct {
+		A float64 `qs:"a,fmt=z"`
+	}{1})
+	if err == nil {
+		t.Errorf("Values with invalid float format did not return error")
+	}
+}
+
+func TestFloatFormat_RoundTrip(t *testing.T) {
+	type Prices struct {
+		A float64 `qs:"a,fixed"`
+		B float64 `qs:"b,decimal,fmt=e"`
+		C float32 `qs:"c,fmt=x"`
+		D float64 `qs:"d"`
+	}
+	input := Prices{1e21, 3, 0.25, math.Inf(1)}
+	values, err := Values(input)
+	if err != nil {
+		t.Fatalf("Values returned error: %v", err)
+	}
+	var got Prices
+	if err := Unmarshal(values, &got); err != nil {
+		t.Fatalf("Unmarshal(%v) returned error: %v", values, err)
+	}
+	if got != input {
+		t.Errorf("Unmarshal(%v) = %+v, want %+v", values, got, input)
+	}
+}
@@ -0,0 +1,185 @@
+package query
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ArrayFormat 数组、切片的编码格式
+type ArrayFormat int
+
+const (
+	// ArrayIndices 带下标的格式，如 ids[0]=1&ids[1]=2，默认格式
+	ArrayIndices ArrayFormat = iota + 1
+	// ArrayBrackets 空中括号的格式，如 ids[]=1&ids[]=2
+	ArrayBrackets
+	// ArrayRepeat 重复参数名的格式，如 ids=1&ids=2
+	ArrayRepeat
+	// ArrayComma 逗号分隔的格式，如 ids=1,2
+	ArrayComma
+	// ArraySpace 空格分隔的格式，如 ids=1%202
+	ArraySpace
+	// ArrayPipe 竖线分隔的格式，如 ids=1|2
+	ArrayPipe
+	// ArrayDelimited 自定义分隔符的格式，分隔符由WithArrayDelimiter或标签选项del=指定
+	ArrayDelimited
+)
+
+// arrayFormatOptions 标签选项与数组格式的对应关系
+var arrayFormatOptions = map[string]ArrayFormat{
+	"indices":  ArrayIndices,
+	"brackets": ArrayBrackets,
+	"repeat":   ArrayRepeat,
+	"comma":    ArrayComma,
+	"space":    ArraySpace,
+	"pipe":     ArrayPipe,
+}
+
+// delimiter 返回分隔格式使用的分隔符，非分隔格式返回空字符串
+func (f ArrayFormat) delimiter(custom string) string {
+	switch f {
+	case ArrayComma:
+		return ","
+	case ArraySpace:
+		return " "
+	case ArrayPipe:
+		return "|"
+	case ArrayDelimited:
+		return custom
+	}
+	return ""
+}
+
+// fieldFormat 字段标签中控制值格式的选项，作用于字段的值以及其中切片、map的元素，
+// 嵌套的结构体字段使用各自标签的选项
+type fieldFormat struct {
+	arrayFormat ArrayFormat
+	delimiter   string
+	// 时间格式，可以是TimeUnix、TimeUnixMilli
+	timeLayout string
+	// 使用fmt.Stringer输出
+	stringer bool
+	// 浮点数的格式
+	float floatStyle
+	// 布尔值的格式
+	bool boolStyle
+	// 字节切片、字节数组的编码格式
+	bytes BytesFormat
+	// time.Duration的单位，为0时使用time.Duration.String的格式
+	duration time.Duration
+}
+
+// parseFieldFormat 解析标签选项中的格式选项，没有格式选项时返回nil
+func parseFieldFormat(opts tagOptions) *fieldFormat {
+	var ff fieldFormat
+	found := false
+	for _, opt := range opts {
+		if f, ok := arrayFormatOptions[opt]; ok {
+			ff.arrayFormat = f
+			found = true
+			continue
+		}
+		if strings.HasPrefix(opt, "del=") {
+			ff.arrayFormat = ArrayDelimited
+			ff.delimiter = strings.TrimPrefix(opt, "del=")
+			found = true
+			continue
+		}
+		if f, ok := bytesFormatOptions[opt]; ok {
+			ff.bytes = f
+			found = true
+			continue
+		}
+		if unit, ok := durationFormatOptions[opt]; ok {
+			ff.duration = unit
+			found = true
+			continue
+		}
+		if layout, ok := timeFormatOptions[opt]; ok {
+			ff.timeLayout = layout
+			found = true
+			continue
+		}
+		if strings.HasPrefix(opt, "layout=") {
+			ff.timeLayout = strings.TrimPrefix(opt, "layout=")
+			found = true
+			continue
+		}
+		if opt == "string" {
+			ff.stringer = true
+			found = true
+			continue
+		}
+		if ff.float.parseFloatOption(opt) || ff.bool.parseBoolOption(opt) {
+			found = true
+		}
+	}
+	if !found {
+		return nil
+	}
+	return &ff
+}
+
+// arrayStyle 返回当前域使用的数组格式及分隔符，字段未指定时使用全局配置
+func (o *options) arrayStyle(scope ScopeOptions) (ArrayFormat, string) {
+	if scope.format != nil && scope.format.arrayFormat != 0 {
+		return scope.format.arrayFormat, scope.format.arrayFormat.delimiter(scope.format.delimiter)
+	}
+	return o.arrayFormat, o.arrayFormat.delimiter(o.delimiter)
+}
+
+const (
+	// TimeUnix 以秒为单位的时间戳，可用于WithTimeFormat及标签选项unix
+	TimeUnix = "unix"
+	// TimeUnixMilli 以毫秒为单位的时间戳，可用于WithTimeFormat及标签选项unixmilli
+	TimeUnixMilli = "unixmilli"
+)
+
+// timeFormatOptions 标签选项与时间格式的对应关系
+var timeFormatOptions = map[string]string{
+	"unix":        TimeUnix,
+	"unixmilli":   TimeUnixMilli,
+	"rfc3339":     time.RFC3339,
+	"rfc3339nano": time.RFC3339Nano,
+}
+
+// timeLayoutOf 返回当前域使用的时间格式，字段未指定时使用全局配置
+func (o *options) timeLayoutOf(scope ScopeOptions) string {
+	if scope.format != nil && scope.format.timeLayout != "" {
+		return scope.format.timeLayout
+	}
+	return o.timeLayout
+}
+
+// formatTime 按格式输出时间
+func formatTime(t time.Time, layout string) string {
+	switch layout {
+	case TimeUnix:
+		return strconv.FormatInt(t.Unix(), 10)
+	case TimeUnixMilli:
+		return strconv.FormatInt(t.Unix()*1e3+int64(t.Nanosecond())/1e6, 10)
+	}
+	return t.Format(layout)
+}
+
+// parseTime 按格式解析时间，时间戳解析为UTC时间
+func parseTime(s, layout string) (time.Time, error) {
+	switch layout {
+	case TimeUnix, TimeUnixMilli:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if layout == TimeUnix {
+			return time.Unix(n, 0).UTC(), nil
+		}
+		return time.Unix(n/1e3, n%1e3*1e6).UTC(), nil
+	}
+	return time.Parse(layout, s)
+}
+
+// useStringer 当前域是否使用fmt.Stringer输出
+func (o *options) useStringer(scope ScopeOptions) bool {
+	return o.stringer || (scope.format != nil && scope.format.stringer)
+}
@@ -0,0 +1,344 @@
+package query
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestValues_ArrayFormat(t *testing.T) {
+	type Item struct {
+		Name string `qs:"name"`
+	}
+
+	tests := []struct {
+		input interface{}
+		want  url.Values
+	}{
+		{
+			struct {
+				V []int `qs:"ids,indices"`
+			}{[]int{1, 2}},
+			url.Values{"ids[0]": {"1"}, "ids[1]": {"2"}},
+		},
+		{
+			struct {
+				V []int `qs:"ids,brackets"`
+			}{[]int{1, 2}},
+			url.Values{"ids[]": {"1", "2"}},
+		},
+		{
+			struct {
+				V []int `qs:"ids,repeat"`
+			}{[]int{1, 2}},
+			url.Values{"ids": {"1", "2"}},
+		},
+		{
+			struct {
+				V [3]int `qs:"ids,comma"`
+			}{[3]int{1, 2, 3}},
+			url.Values{"ids": {"1,2,3"}},
+		},
+		{
+			struct {
+				V []string `qs:"ids,space"`
+			}{[]string{"a", "b"}},
+			url.Values{"ids": {"a b"}},
+		},
+		{
+			struct {
+				V []string `qs:"ids,pipe"`
+			}{[]string{"a", "b"}},
+			url.Values{"ids": {"a|b"}},
+		},
+		{
+			struct {
+				V []string `qs:"ids,del=;"`
+			}{[]string{"a", "b"}},
+			url.Values{"ids": {"a;b"}},
+		},
+		{
+			struct {
+				V []string `qs:"ids,comma"`
+			}{[]string{}},
+			url.Values{},
+		},
+
+		// nested in structs and maps
+		{
+			struct {
+				F struct {
+					V []int `qs:"ids,comma"`
+				} `qs:"filter"`
+			}{struct {
+				V []int `qs:"ids,comma"`
+			}{[]int{1, 2}}},
+			url.Values{"filter[ids]": {"1,2"}},
+		},
+		{
+			struct {
+				M map[string][]int `qs:"m,repeat"`
+			}{map[string][]int{"a": {1, 2}}},
+			url.Values{"m[a]": {"1", "2"}},
+		},
+		{
+			struct {
+				V [][]int `qs:"v,brackets"`
+			}{[][]int{{1, 2}, {3}}},
+			url.Values{"v[][]": {"1", "2", "3"}},
+		},
+
+		// format applies to the field, not to fields of nested structs
+		{
+			struct {
+				Items []Item `qs:"items,repeat"`
+			}{[]Item{{"a"}, {"b"}}},
+			url.Values{"items[name]": {"a", "b"}},
+		},
+		{
+			struct {
+				Items []struct {
+					Tags []string `qs:"tags"`
+				} `qs:"items,repeat"`
+			}{[]struct {
+				Tags []string `qs:"tags"`
+			}{{[]string{"x"}}}},
+			url.Values{"items[tags][0]": {"x"}},
+		},
+	}
+
+	for _, tt := range tests {
+		testValue(t, tt.input, tt.want)
+	}
+}
+
+func TestEncoder_ArrayFormat(t *testing.T) {
+	input := struct {
+		A []int `qs:"a"`
+		B []int `qs:"b,indices"`
+	}{[]int{1, 2}, []int{3}}
+
+	tests := []struct {
+		enc  *QueryEncoder
+		want url.Values
+	}{
+		{NewEncoder(WithArrayFormat(ArrayRepeat)), url.Values{"a": {"1", "2"}, "b[0]": {"3"}}},
+		{NewEncoder(WithArrayFormat(ArrayBrackets)), url.Values{"a[]": {"1", "2"}, "b[0]": {"3"}}},
+		{NewEncoder(WithArrayFormat(ArrayComma)), url.Values{"a": {"1,2"}, "b[0]": {"3"}}},
+		{NewEncoder(WithArrayDelimiter("~")), url.Values{"a": {"1~2"}, "b[0]": {"3"}}},
+	}
+
+	for _, tt := range tests {
+		testEncoderValue(t, tt.enc, input, tt.want)
+	}
+
+	// top-level maps have no field options, the default format is used.
+	testEncoderValue(t, NewEncoder(WithArrayFormat(ArrayComma)),
+		map[string][]int{"ids": {1, 2}},
+		url.Values{"ids": {"1,2"}})
+}
+
+func TestUnmarshal_ArrayFormat(t *testing.T) {
+	tests := []struct {
+		input url.Values
+		want  interface{}
+	}{
+		{
+			url.Values{"ids[]": {"1", "2"}},
+			struct {
+				V []int `qs:"ids,brackets"`
+			}{[]int{1, 2}},
+		},
+		{
+			url.Values{"ids": {"1", "2"}},
+			struct {
+				V []int `qs:"ids,repeat"`
+			}{[]int{1, 2}},
+		},
+		{
+			url.Values{"ids": {"1,2", "3"}},
+			struct {
+				V []int `qs:"ids,comma"`
+			}{[]int{1, 2, 3}},
+		},
+		{
+			url.Values{"ids": {"a|b"}},
+			struct {
+				V [2]string `qs:"ids,pipe"`
+			}{[2]string{"a", "b"}},
+		},
+		{
+			url.Values{"ids": {"a;b"}},
+			struct {
+				V []string `qs:"ids,del=;"`
+			}{[]string{"a", "b"}},
+		},
+		{
+			url.Values{"m[a]": {"1,2"}},
+			struct {
+				M map[string][]int `qs:"m,comma"`
+			}{map[string][]int{"a": {1, 2}}},
+		},
+	}
+
+	for _, tt := range tests {
+		testUnmarshal(t, tt.input, tt.want)
+	}
+
+	var s struct {
+		V []string `qs:"v"`
+	}
+	if err := NewDecoder(WithArrayFormat(ArraySpace)).Unmarshal(url.Values{"v": {"a b"}}, &s); err != nil {
+		t.Fatalf("Unmarshal returned error: %v", err)
+	}
+	if len(s.V) != 2 || s.V[0] != "a" || s.V[1] != "b" {
+		t.Errorf("Unmarshal with ArraySpace = %q, want [a b]", s.V)
+	}
+}
+
+func TestParseFieldFormat(t *testing.T) {
+	tests := []struct {
+		opts tagOptions
+		want *fieldFormat
+	}{
+		{nil, nil},
+		{tagOptions{"omitempty"}, nil},
+		{tagOptions{"comma"}, &fieldFormat{arrayFormat: ArrayComma}},
+		{tagOptions{"omitempty", "repeat"}, &fieldFormat{arrayFormat: ArrayRepeat}},
+		{tagOptions{"del=;"}, &fieldFormat{arrayFormat: ArrayDelimited, delimiter: ";"}},
+	}
+	for _, tt := range tests {
+		got := parseFieldFormat(tt.opts)
+		if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+			t.Errorf("parseFieldFormat(%q) = %+v, want %+v", tt.opts, got, tt.want)
+		}
+	}
+}
+
+func TestValues_TimeFormat(t *testing.T) {
+	ts := time.Date(2022, 02, 11, 16, 39, 02, 123456789, time.UTC)
+
+	tests := []struct {
+		input interface{}
+		want  url.Values
+	}{
+		{
+			struct {
+				T time.Time `qs:"since,unix"`
+			}{ts},
+			url.Values{"since": {"1644597542"}},
+		},
+		{
+			struct {
+				T time.Time `qs:"ts,unixmilli"`
+			}{ts},
+			url.Values{"ts": {"1644597542123"}},
+		},
+		{
+			struct {
+				T time.Time `qs:"date,layout=2006-01-02"`
+			}{ts},
+			url.Values{"date": {"2022-02-11"}},
+		},
+		{
+			struct {
+				T time.Time `qs:"at,rfc3339"`
+			}{ts.In(time.FixedZone("", 8*3600))},
+			url.Values{"at": {"2022-02-12T00:39:02+08:00"}},
+		},
+		{
+			struct {
+				T time.Time `qs:"at,rfc3339nano"`
+			}{ts},
+			url.Values{"at": {"2022-02-11T16:39:02.123456789Z"}},
+		},
+
+		// pointers, slices and maps of times
+		{
+			struct {
+				T *time.Time `qs:"since,unix"`
+			}{&ts},
+			url.Values{"since": {"1644597542"}},
+		},
+		{
+			struct {
+				T *time.Time `qs:"since,unix,omitempty"`
+			}{},
+			url.Values{},
+		},
+		{
+			struct {
+				T []time.Time `qs:"days,layout=2006-01-02,comma"`
+			}{[]time.Time{ts, ts.AddDate(0, 0, 1)}},
+			url.Values{"days": {"2022-02-11,2022-02-12"}},
+		},
+		{
+			struct {
+				T map[string]*time.Time `qs:"range,unix"`
+			}{map[string]*time.Time{"from": &ts}},
+			url.Values{"range[from]": {"1644597542"}},
+		},
+	}
+
+	for _, tt := range tests {
+		testValue(t, tt.input, tt.want)
+	}
+
+	testEncoderValue(t, NewEncoder(WithTimeFormat(TimeUnix)), struct {
+		A time.Time
+		B time.Time `qs:",layout=2006"`
+	}{ts, ts}, url.Values{"A": {"1644597542"}, "B": {"2022"}})
+}
+
+func TestUnmarshal_TimeFormat(t *testing.T) {
+	ts := time.Date(2022, 02, 11, 16, 39, 02, 0, time.UTC)
+	ms := time.Date(2022, 02, 11, 16, 39, 02, 123000000, time.UTC)
+	day := time.Date(2022, 02, 11, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		input url.Values
+		want  interface{}
+	}{
+		{
+			url.Values{"since": {"1644597542"}},
+			struct {
+				T time.Time `qs:"since,unix"`
+			}{ts},
+		},
+		{
+			url.Values{"ts": {"1644597542123"}},
+			struct {
+				T *time.Time `qs:"ts,unixmilli"`
+			}{&ms},
+		},
+		{
+			url.Values{"date": {"2022-02-11"}},
+			struct {
+				T time.Time `qs:"date,layout=2006-01-02"`
+			}{day},
+		},
+		{
+			url.Values{"at": {"2022-02-11T16:39:02Z"}},
+			struct {
+				T time.Time `qs:"at,rfc3339"`
+			}{ts},
+		},
+		{
+			url.Values{"days[0]": {"2022-02-11"}},
+			struct {
+				T []time.Time `qs:"days,layout=2006-01-02"`
+			}{[]time.Time{day}},
+		},
+	}
+
+	for _, tt := range tests {
+		testUnmarshal(t, tt.input, tt.want)
+	}
+
+	var s struct {
+		T time.Time `qs:"since,unix"`
+	}
+	if err := Unmarshal(url.Values{"since": {"yesterday"}}, &s); err == nil {
+		t.Errorf("expected Unmarshal() to return an error on invalid timestamp")
+	}
+}
@@ -0,0 +1,101 @@
+package query
+
+import "strings"
+
+// NestingStyle 嵌套参数名的格式
+type NestingStyle int
+
+const (
+	// NestBrackets 中括号格式，如 filter[owner][name]、items[0][name]，默认格式
+	NestBrackets NestingStyle = iota + 1
+	// NestDots 点号格式，数组下标仍使用中括号，如 filter.owner.name、items[0].name
+	NestDots
+)
+
+// KeyJoiner 拼接上级参数名与下级名称，index表示下级名称为数组下标，
+// 顶层的上级参数名为空字符串
+type KeyJoiner func(parent, child string, index bool) string
+
+// KeySplitter 将参数名拆分为各级名称，是KeyJoiner的逆过程
+type KeySplitter func(key string) []string
+
+// joinBrackets 中括号格式
+func joinBrackets(parent, child string, index bool) string {
+	if parent == "" && !index {
+		return child
+	}
+	return parent + "[" + child + "]"
+}
+
+// joinDots 点号格式
+func joinDots(parent, child string, index bool) string {
+	if index {
+		return parent + "[" + child + "]"
+	}
+	if parent == "" {
+		return child
+	}
+	return parent + "." + child
+}
+
+// separatorJoiner 使用分隔符拼接，数组下标同样使用分隔符，如 items__0__name
+func separatorJoiner(sep string) KeyJoiner {
+	return func(parent, child string, index bool) string {
+		if parent == "" && !index {
+			return child
+		}
+		return parent + sep + child
+	}
+}
+
+// splitBrackets 拆分中括号格式的参数名，如 nest[a][value] 拆分为 nest、a、value
+// 格式不正确的参数名整体作为一段
+func splitBrackets(key string) []string {
+	i := strings.IndexByte(key, '[')
+	if i <= 0 {
+		return []string{key}
+	}
+	segs := []string{key[:i]}
+	rest := key[i:]
+	for len(rest) > 0 {
+		if rest[0] != '[' {
+			return []string{key}
+		}
+		j := strings.IndexByte(rest, ']')
+		if j < 0 {
+			return []string{key}
+		}
+		segs = append(segs, rest[1:j])
+		rest = rest[j+1:]
+	}
+	return segs
+}
+
+// splitDots 拆分点号格式的参数名，如 items[0].name 拆分为 items、0、name
+// 格式不正确的参数名整体作为一段
+func splitDots(key string) []string {
+	var segs []string
+	for _, part := range strings.Split(key, ".") {
+		sub := splitBrackets(part)
+		if part == "" || (len(sub) == 1 && strings.ContainsAny(part, "[]")) {
+			return []string{key}
+		}
+		segs = append(segs, sub...)
+	}
+	return segs
+}
+
+// separatorSplitter 按分隔符拆分，末尾的空中括号 ids[] 单独作为一段
+func separatorSplitter(sep string) KeySplitter {
+	return func(key string) []string {
+		var segs []string
+		for _, part := range strings.Split(key, sep) {
+			if strings.HasSuffix(part, "[]") && len(part) > 2 {
+				segs = append(segs, strings.TrimSuffix(part, "[]"), "")
+				continue
+			}
+			segs = append(segs, part)
+		}
+		return segs
+	}
+}
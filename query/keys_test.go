@@ -0,0 +1,174 @@
+package query
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type nestingItem struct {
+	Name string `qs:"name"`
+}
+
+type nestingFilter struct {
+	Owner struct {
+		Name string `qs:"name"`
+	} `qs:"owner"`
+	Items []nestingItem   `qs:"items"`
+	Tags  map[string]bool `qs:"tags"`
+}
+
+func newNestingFilter() nestingFilter {
+	var f nestingFilter
+	f.Owner.Name = "o"
+	f.Items = []nestingItem{{"a"}}
+	f.Tags = map[string]bool{"x": true}
+	return f
+}
+
+func TestEncoder_NestingStyle(t *testing.T) {
+	input := struct {
+		Filter nestingFilter `qs:"filter"`
+	}{newNestingFilter()}
+
+	upper := func(parent, child string, index bool) string {
+		if parent == "" {
+			return strings.ToUpper(child)
+		}
+		return parent + "/" + strings.ToUpper(child)
+	}
+
+	tests := []struct {
+		enc  *QueryEncoder
+		want url.Values
+	}{
+		{
+			NewEncoder(),
+			url.Values{
+				"filter[owner][name]":    {"o"},
+				"filter[items][0][name]": {"a"},
+				"filter[tags][x]":        {"true"},
+			},
+		},
+		{
+			NewEncoder(WithNestingStyle(NestDots)),
+			url.Values{
+				"filter.owner.name":    {"o"},
+				"filter.items[0].name": {"a"},
+				"filter.tags.x":        {"true"},
+			},
+		},
+		{
+			NewEncoder(WithNestSeparator("__")),
+			url.Values{
+				"filter__owner__name":    {"o"},
+				"filter__items__0__name": {"a"},
+				"filter__tags__x":        {"true"},
+			},
+		},
+		{
+			NewEncoder(WithNestSeparator(".")),
+			url.Values{
+				"filter.owner.name":   {"o"},
+				"filter.items.0.name": {"a"},
+				"filter.tags.x":       {"true"},
+			},
+		},
+		{
+			NewEncoder(WithKeyJoiner(upper, nil)),
+			url.Values{
+				"FILTER/OWNER/NAME":   {"o"},
+				"FILTER/ITEMS/0/NAME": {"a"},
+				"FILTER/TAGS/X":       {"true"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		testEncoderValue(t, tt.enc, input, tt.want)
+	}
+}
+
+func TestDecoder_NestingStyle(t *testing.T) {
+	type Wrapper struct {
+		Filter nestingFilter `qs:"filter"`
+	}
+	want := Wrapper{newNestingFilter()}
+
+	for _, opt := range []Option{
+		WithNestingStyle(NestBrackets),
+		WithNestingStyle(NestDots),
+		WithNestSeparator("__"),
+		WithNestSeparator("."),
+	} {
+		v, err := NewEncoder(opt).Values(want)
+		if err != nil {
+			t.Fatalf("Values returned error: %v", err)
+		}
+		var got Wrapper
+		if err := NewDecoder(opt).Unmarshal(v, &got); err != nil {
+			t.Fatalf("Unmarshal(%v) returned error: %v", v, err)
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("Unmarshal(%v) = %+v, want %+v", v, got, want)
+		}
+	}
+}
+
+func TestSplitBrackets(t *testing.T) {
+	tests := []struct {
+		key  string
+		want []string
+	}{
+		{"a", []string{"a"}},
+		{"a[b]", []string{"a", "b"}},
+		{"nest[a][value]", []string{"nest", "a", "value"}},
+		{"a[]", []string{"a", ""}},
+		{"a[b", []string{"a[b"}},
+		{"a[b]c", []string{"a[b]c"}},
+		{"[a]", []string{"[a]"}},
+	}
+	for _, tt := range tests {
+		if got := splitBrackets(tt.key); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitBrackets(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestSplitDots(t *testing.T) {
+	tests := []struct {
+		key  string
+		want []string
+	}{
+		{"a", []string{"a"}},
+		{"a.b", []string{"a", "b"}},
+		{"items[0].name", []string{"items", "0", "name"}},
+		{"a.b[1][2]", []string{"a", "b", "1", "2"}},
+		{"ids[]", []string{"ids", ""}},
+		{"a..b", []string{"a..b"}},
+		{"a.b[c", []string{"a.b[c"}},
+	}
+	for _, tt := range tests {
+		if got := splitDots(tt.key); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitDots(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestSeparatorSplitter(t *testing.T) {
+	split := separatorSplitter("__")
+	tests := []struct {
+		key  string
+		want []string
+	}{
+		{"a", []string{"a"}},
+		{"a__b__0", []string{"a", "b", "0"}},
+		{"a__ids[]", []string{"a", "ids", ""}},
+	}
+	for _, tt := range tests {
+		if got := split(tt.key); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("separatorSplitter(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// MergePolicy 编码结果与URL中已有参数冲突时的处理方式
+type MergePolicy int
+
+const (
+	// MergeOverride 编码结果覆盖URL中冲突的参数
+	MergeOverride MergePolicy = iota
+	// MergeAppend 保留URL中的参数并追加编码结果，带下标的数组元素接在已有元素之后
+	MergeAppend
+	// MergeYield 保留URL中的参数，忽略编码结果中冲突的参数
+	MergeYield
+)
+
+// AppendToURL 使用默认编码器对v进行编码，并与base中已有的参数合并
+func AppendToURL(base interface{}, v interface{}, policy MergePolicy) (*url.URL, error) {
+	return defaultEncoder.AppendToURL(base, v, policy)
+}
+
+// AppendToURL 对v进行编码，并与base中已有的参数合并，base为string或*url.URL，不会修改base。
+// 参数按嵌套的层级合并：同一结构体、map下的不同字段互不冲突，数组及单个值作为整体，
+// 参数名相同或一方是另一方的上级时视为冲突，按policy处理
+func (e *QueryEncoder) AppendToURL(base interface{}, v interface{}, policy MergePolicy) (*url.URL, error) {
+	var u url.URL
+	switch b := base.(type) {
+	case string:
+		p, err := url.Parse(b)
+		if err != nil {
+			return nil, err
+		}
+		u = *p
+	case *url.URL:
+		if b == nil {
+			return nil, fmt.Errorf("append to url: nil *url.URL")
+		}
+		u = *b
+	default:
+		return nil, fmt.Errorf("append to url: unsupported base type %T", base)
+	}
+
+	existing, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := e.Values(v)
+	if err != nil {
+		return nil, err
+	}
+
+	u.RawQuery = e.merge(existing, encoded, policy).Encode()
+	u.ForceQuery = false
+	return &u, nil
+}
+
+// mergeParam 参数名拆分后的各级名称，unit为冲突判断的单元，即第一个数组下标之前的部分
+type mergeParam struct {
+	key  string
+	segs []string
+	unit []string
+}
+
+// mergeParams 拆分参数名
+func (e *QueryEncoder) mergeParams(values url.Values) []mergeParam {
+	params := make([]mergeParam, 0, len(values))
+	for key := range values {
+		segs := []string{key}
+		if e.opts.splitter != nil {
+			segs = e.opts.splitter(key)
+		}
+		unit := segs
+		for i := 1; i < len(segs); i++ {
+			if isIndexSeg(segs[i]) {
+				unit = segs[:i]
+				break
+			}
+		}
+		params = append(params, mergeParam{key, segs, unit})
+	}
+	return params
+}
+
+// isIndexSeg 是否为数组下标，空字符串表示 ids[] 格式的元素
+func isIndexSeg(seg string) bool {
+	if seg == "" {
+		return true
+	}
+	n, err := strconv.Atoi(seg)
+	return err == nil && n >= 0
+}
+
+// conflicts 两个单元相同或一方是另一方的上级
+func conflicts(a, b []string) bool {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// conflictsAny p是否与params中的任一参数冲突
+func conflictsAny(p mergeParam, params []mergeParam) bool {
+	for _, q := range params {
+		if conflicts(p.unit, q.unit) {
+			return true
+		}
+	}
+	return false
+}
+
+// merge 按policy合并已有参数与编码结果
+func (e *QueryEncoder) merge(existing, encoded url.Values, policy MergePolicy) url.Values {
+	oldParams, newParams := e.mergeParams(existing), e.mergeParams(encoded)
+	merged := make(url.Values, len(existing)+len(encoded))
+
+	switch policy {
+	case MergeAppend:
+		for k, vs := range existing {
+			merged[k] = append([]string(nil), vs...)
+		}
+		offsets := indexOffsets(oldParams)
+		for _, p := range newParams {
+			key := p.key
+			if n := len(p.unit); n < len(p.segs) && p.segs[n] != "" {
+				if off, ok := offsets[unitKey(p.unit)]; ok {
+					key = e.shiftIndex(p, off)
+				}
+			}
+			merged[key] = append(merged[key], encoded[p.key]...)
+		}
+	case MergeYield:
+		for k, vs := range existing {
+			merged[k] = vs
+		}
+		for _, p := range newParams {
+			if !conflictsAny(p, oldParams) {
+				merged[p.key] = encoded[p.key]
+			}
+		}
+	default:
+		for _, p := range oldParams {
+			if !conflictsAny(p, newParams) {
+				merged[p.key] = existing[p.key]
+			}
+		}
+		for k, vs := range encoded {
+			merged[k] = vs
+		}
+	}
+	return merged
+}
+
+// unitKey 单元的唯一标识
+func unitKey(unit []string) string {
+	return fmt.Sprintf("%q", unit)
+}
+
+// indexOffsets 已有数组追加元素时下标的偏移量，即已有的最大下标加一
+func indexOffsets(params []mergeParam) map[string]int {
+	offsets := make(map[string]int)
+	for _, p := range params {
+		n := len(p.unit)
+		if n == len(p.segs) || p.segs[n] == "" {
+			continue
+		}
+		i, _ := strconv.Atoi(p.segs[n])
+		k := unitKey(p.unit)
+		if i+1 > offsets[k] {
+			offsets[k] = i + 1
+		}
+	}
+	return offsets
+}
+
+// shiftIndex 将参数的数组下标加上off，并使用编码器的格式重新拼接参数名
+func (e *QueryEncoder) shiftIndex(p mergeParam, off int) string {
+	n := len(p.unit)
+	i, _ := strconv.Atoi(p.segs[n])
+	segs := append([]string(nil), p.segs...)
+	segs[n] = strconv.Itoa(i + off)
+
+	key := ""
+	for j, seg := range segs {
+		key = e.opts.joiner(key, seg, j > 0 && isIndexSeg(seg))
+	}
+	return key
+}
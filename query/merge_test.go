@@ -0,0 +1,122 @@
+package query
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestAppendToURL(t *testing.T) {
+	type Filter struct {
+		Name string `qs:"name"`
+		Tags []int  `qs:"tags"`
+	}
+	type Search struct {
+		Q      string  `qs:"q"`
+		Filter *Filter `qs:"filter,omitempty"`
+	}
+
+	tests := []struct {
+		base   string
+		input  interface{}
+		policy MergePolicy
+		want   string
+	}{
+		{
+			"https://example.com/search",
+			Search{Q: "go"},
+			MergeOverride,
+			"https://example.com/search?q=go",
+		},
+		{
+			"/search?q=old&page=2#top",
+			Search{Q: "new"},
+			MergeOverride,
+			"/search?page=2&q=new#top",
+		},
+		{
+			// 同一结构体下的不同字段合并，数组整体覆盖
+			"/search?filter[owner]=me&filter[tags][0]=1&filter[tags][1]=2",
+			Search{Q: "go", Filter: &Filter{Name: "a", Tags: []int{3}}},
+			MergeOverride,
+			"/search?filter%5Bname%5D=a&filter%5Bowner%5D=me&filter%5Btags%5D%5B0%5D=3&q=go",
+		},
+		{
+			// 单个值与嵌套参数冲突
+			"/search?filter=x",
+			Search{Filter: &Filter{Name: "a"}},
+			MergeOverride,
+			"/search?filter%5Bname%5D=a&q=",
+		},
+		{
+			"/search?q=old&filter[tags][0]=1&filter[tags][1]=2",
+			Search{Q: "new", Filter: &Filter{Name: "a", Tags: []int{3, 4}}},
+			MergeAppend,
+			"/search?filter%5Bname%5D=a&filter%5Btags%5D%5B0%5D=1&filter%5Btags%5D%5B1%5D=2&filter%5Btags%5D%5B2%5D=3&filter%5Btags%5D%5B3%5D=4&q=old&q=new",
+		},
+		{
+			"/search?q=old&filter[tags][0]=1",
+			Search{Q: "new", Filter: &Filter{Name: "a", Tags: []int{3}}},
+			MergeYield,
+			"/search?filter%5Bname%5D=a&filter%5Btags%5D%5B0%5D=1&q=old",
+		},
+		{
+			"/search?filter[name]=b",
+			Search{Filter: &Filter{Name: "a"}},
+			MergeYield,
+			"/search?filter%5Bname%5D=b&q=",
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := AppendToURL(tt.base, tt.input, tt.policy)
+		if err != nil {
+			t.Errorf("AppendToURL(%q, %v, %v) returned error: %v", tt.base, tt.input, tt.policy, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("AppendToURL(%q, %v, %v) = %q, want %q", tt.base, tt.input, tt.policy, got, tt.want)
+		}
+	}
+}
+
+func TestAppendToURL_NestingStyle(t *testing.T) {
+	enc := NewEncoder(WithNestingStyle(NestDots))
+	input := struct {
+		Items []struct {
+			Name string `qs:"name"`
+		} `qs:"items"`
+	}{Items: []struct {
+		Name string `qs:"name"`
+	}{{"c"}}}
+
+	got, err := enc.AppendToURL("/?items[0].name=a&items[1].name=b", input, MergeAppend)
+	if err != nil {
+		t.Fatalf("AppendToURL returned error: %v", err)
+	}
+	want := url.Values{"items[0].name": {"a"}, "items[1].name": {"b"}, "items[2].name": {"c"}}
+	if got.RawQuery != want.Encode() {
+		t.Errorf("AppendToURL = %q, want %q", got.RawQuery, want.Encode())
+	}
+}
+
+func TestAppendToURL_Base(t *testing.T) {
+	base, _ := url.Parse("/search?q=old")
+	got, err := AppendToURL(base, struct {
+		Q string `qs:"q"`
+	}{"new"}, MergeOverride)
+	if err != nil {
+		t.Fatalf("AppendToURL returned error: %v", err)
+	}
+	if got.String() != "/search?q=new" {
+		t.Errorf("AppendToURL = %q, want %q", got, "/search?q=new")
+	}
+	if base.RawQuery != "q=old" {
+		t.Errorf("AppendToURL modified base: %q", base)
+	}
+
+	for _, base := range []interface{}{nil, 1, (*url.URL)(nil), "%zz", "/?a=%zz"} {
+		if _, err := AppendToURL(base, struct{}{}, MergeOverride); err == nil {
+			t.Errorf("AppendToURL(%#v) did not return error", base)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+//go:build go1.18
+// +build go1.18
+
+package query
+
+import (
+	"net/netip"
+	"net/url"
+	"testing"
+)
+
+func TestValues_Netip(t *testing.T) {
+	type Peer struct {
+		Addr   netip.Addr
+		Prefix netip.Prefix
+		Port   netip.AddrPort
+	}
+	input := Peer{
+		Addr:   netip.MustParseAddr("fe80::1"),
+		Prefix: netip.MustParsePrefix("10.0.0.0/8"),
+		Port:   netip.MustParseAddrPort("127.0.0.1:80"),
+	}
+	want := url.Values{"Addr": {"fe80::1"}, "Prefix": {"10.0.0.0/8"}, "Port": {"127.0.0.1:80"}}
+	testValue(t, input, want)
+
+	var got Peer
+	if err := Unmarshal(want, &got); err != nil {
+		t.Fatalf("Unmarshal(%v) returned error: %v", want, err)
+	}
+	if got != input {
+		t.Errorf("Unmarshal(%v) = %v, want %v", want, got, input)
+	}
+}
@@ -0,0 +1,210 @@
+package query
+
+// defaultTagKey 默认的结构体标签名称
+const defaultTagKey = "qs"
+
+// defaultTimeLayout 默认的时间格式
+const defaultTimeLayout = "2006-01-02 15:04:05"
+
+// options 编码器、解码器的配置
+type options struct {
+	timeLayout string
+	maxLevel   int
+	// 解码切片时允许缺失的下标个数
+	maxSliceHoles int
+	tagKey        string
+	omitPolicy    OmitPolicy
+	// 数组格式，分隔符仅在ArrayDelimited时使用
+	arrayFormat ArrayFormat
+	delimiter   string
+	// 使用fmt.Stringer输出
+	stringer bool
+	// 浮点数的格式及NaN、无穷的处理方式
+	floatStyle floatStyle
+	nonFinite  NonFinitePolicy
+	// 布尔值的格式
+	boolStyle boolStyle
+	// 字节切片、字节数组的编码格式
+	bytesFormat BytesFormat
+	// 有序输出时map参数名的比较函数
+	mapKeyLess func(a, b string) bool
+	// 嵌套参数名的拼接、拆分方式
+	joiner   KeyJoiner
+	splitter KeySplitter
+}
+
+// Option 编码器、解码器的配置项
+type Option func(*options)
+
+// newOptions 在默认配置的基础上应用配置项
+func newOptions(opts []Option) options {
+	o := options{
+		timeLayout:    defaultTimeLayout,
+		maxLevel:      maxLevel,
+		maxSliceHoles: defaultMaxSliceHoles,
+		tagKey:        defaultTagKey,
+		arrayFormat:   ArrayIndices,
+		joiner:        joinBrackets,
+		splitter:      splitBrackets,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithTimeFormat 设置时间参数的格式，默认为 2006-01-02 15:04:05，也可以是TimeUnix、TimeUnixMilli
+func WithTimeFormat(layout string) Option {
+	return func(o *options) {
+		o.timeLayout = layout
+	}
+}
+
+// UnlimitedDepth 不限制嵌套层数，编码时通过循环引用检测保证结束
+const UnlimitedDepth = 0
+
+// WithMaxDepth 设置最大嵌套层数，默认为7，小于等于0时不限制层数
+func WithMaxDepth(level int) Option {
+	return func(o *options) {
+		o.maxLevel = level
+	}
+}
+
+// defaultMaxSliceHoles 解码切片时默认允许缺失的下标个数
+const defaultMaxSliceHoles = 100
+
+// WithMaxSliceHoles 设置解码切片时允许缺失的下标个数，如 ids[1]=2 缺失下标0，缺失的元素为零值，
+// 超过时返回错误，避免 ids[1000000]=1 分配过大的切片，默认为100
+func WithMaxSliceHoles(n int) Option {
+	return func(o *options) {
+		o.maxSliceHoles = n
+	}
+}
+
+// WithTagKey 设置读取的结构体标签名称，默认为qs
+func WithTagKey(key string) Option {
+	return func(o *options) {
+		o.tagKey = key
+	}
+}
+
+// OmitPolicy 字段未指定omitempty、omitzero、omitnil选项时，编码器默认的忽略策略
+type OmitPolicy int
+
+const (
+	// OmitNone 不忽略字段，默认策略
+	OmitNone OmitPolicy = iota
+	// OmitEmpty 按omitempty处理，忽略空字符串、0、false、nil及长度为0的切片、map
+	OmitEmpty
+	// OmitZero 按omitzero处理，忽略IsZero返回true的值或零值
+	OmitZero
+	// OmitNil 按omitnil处理，只忽略nil指针、接口、切片、map
+	OmitNil
+)
+
+// WithOmitEmpty 未指定忽略选项的字段均按omitempty处理，等同于WithOmitPolicy(OmitEmpty)
+func WithOmitEmpty() Option {
+	return WithOmitPolicy(OmitEmpty)
+}
+
+// WithOmitPolicy 设置字段未指定omitempty、omitzero、omitnil选项时的忽略策略，默认为OmitNone
+func WithOmitPolicy(policy OmitPolicy) Option {
+	return func(o *options) {
+		o.omitPolicy = policy
+	}
+}
+
+// WithArrayFormat 设置数组、切片的默认格式，字段标签中的格式选项优先
+func WithArrayFormat(format ArrayFormat) Option {
+	return func(o *options) {
+		o.arrayFormat = format
+	}
+}
+
+// WithArrayDelimiter 设置数组、切片默认使用自定义分隔符的格式
+func WithArrayDelimiter(delimiter string) Option {
+	return func(o *options) {
+		o.arrayFormat = ArrayDelimited
+		o.delimiter = delimiter
+	}
+}
+
+// WithNestingStyle 设置嵌套参数名的格式，默认为NestBrackets
+func WithNestingStyle(style NestingStyle) Option {
+	return func(o *options) {
+		switch style {
+		case NestDots:
+			o.joiner, o.splitter = joinDots, splitDots
+		default:
+			o.joiner, o.splitter = joinBrackets, splitBrackets
+		}
+	}
+}
+
+// WithNestSeparator 使用分隔符拼接嵌套参数名及数组下标，如 filter__owner__name、items.0.name
+func WithNestSeparator(sep string) Option {
+	return func(o *options) {
+		o.joiner, o.splitter = separatorJoiner(sep), separatorSplitter(sep)
+	}
+}
+
+// WithKeyJoiner 自定义嵌套参数名的拼接方式，split用于解码时拆分参数名，为nil时解码不拆分参数名
+func WithKeyJoiner(join KeyJoiner, split KeySplitter) Option {
+	return func(o *options) {
+		o.joiner, o.splitter = join, split
+	}
+}
+
+// WithStringer 实现了fmt.Stringer的结构体、切片等类型作为单个值输出，不再展开，
+// 优先级低于Encoder、encoding.TextMarshaler，基础类型本身即通过fmt输出
+func WithStringer() Option {
+	return func(o *options) {
+		o.stringer = true
+	}
+}
+
+// WithFloatFormat 设置浮点数的格式，format、prec与strconv.FormatFloat的参数一致，
+// prec为-1时使用最短表示，字段标签中的prec=、fixed、fmt=选项优先
+func WithFloatFormat(format byte, prec int) Option {
+	return func(o *options) {
+		o.floatStyle.format = format
+		o.floatStyle.prec, o.floatStyle.hasPrec = prec, prec >= 0
+	}
+}
+
+// WithFloatDecimal 浮点数总是包含小数点，如 1 输出为 1.0，与标签选项decimal相同
+func WithFloatDecimal() Option {
+	return func(o *options) {
+		o.floatStyle.decimal = true
+	}
+}
+
+// WithNonFinite 设置NaN、正负无穷的编码方式，默认为NonFiniteLiteral
+func WithNonFinite(policy NonFinitePolicy) Option {
+	return func(o *options) {
+		o.nonFinite = policy
+	}
+}
+
+// WithBoolFormat 设置布尔值的输出形式，如 WithBoolFormat("1", "0")、WithBoolFormat("yes", "no")，
+// f为空时值为false的参数不输出，解码时同样接受这两个值，字段标签中的int、bool=选项优先
+func WithBoolFormat(t, f string) Option {
+	return func(o *options) {
+		o.boolStyle = boolStyle{true, t, f}
+	}
+}
+
+// WithBytesFormat 设置字节切片、字节数组的编码格式，默认为BytesElements，
+// 字段标签中的base64、base64url、base64raw、base64urlraw、hex、raw选项优先
+func WithBytesFormat(format BytesFormat) Option {
+	return func(o *options) {
+		o.bytesFormat = format
+	}
+}
+
+// WithMapKeyOrder 设置有序输出时map的排列顺序，less比较的是格式化后的参数名
+func WithMapKeyOrder(less func(a, b string) bool) Option {
+	return func(o *options) {
+		o.mapKeyLess = less
+	}
+}
@@ -0,0 +1,114 @@
+package query
+
+import (
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// test that enc.Values(input) matches want.  If not, report an error on t.
+func testEncoderValue(t *testing.T, enc *QueryEncoder, input interface{}, want url.Values) {
+	v, err := enc.Values(input)
+	if err != nil {
+		t.Errorf("Values(%q) returned error: %v", input, err)
+	}
+	if diff := cmp.Diff(want, v); diff != "" {
+		t.Errorf("Values(%#v) mismatch:\n%s", input, diff)
+	}
+}
+
+func TestEncoder_Options(t *testing.T) {
+	birthday := time.Date(2022, 02, 11, 16, 39, 02, 0, time.UTC)
+	tests := []struct {
+		enc   *QueryEncoder
+		input interface{}
+		want  url.Values
+	}{
+		{
+			NewEncoder(),
+			struct{ T time.Time }{birthday},
+			url.Values{"T": {"2022-02-11 16:39:02"}},
+		},
+		{
+			NewEncoder(WithTimeFormat("2006-01-02")),
+			struct{ T time.Time }{birthday},
+			url.Values{"T": {"2022-02-11"}},
+		},
+		{
+			NewEncoder(WithTagKey("url")),
+			struct {
+				A string `url:"a" qs:"x"`
+				B string `url:"-"`
+				C string `qs:"c"`
+			}{"a", "b", "c"},
+			url.Values{"a": {"a"}, "C": {"c"}},
+		},
+		{
+			NewEncoder(WithOmitEmpty()),
+			struct {
+				A string
+				B int
+				C []string
+				D bool
+			}{A: "a"},
+			url.Values{"A": {"a"}},
+		},
+	}
+
+	for _, tt := range tests {
+		testEncoderValue(t, tt.enc, tt.input, tt.want)
+	}
+}
+
+func TestEncoder_MaxDepth(t *testing.T) {
+	input := map[string]interface{}{
+		"a": map[string]interface{}{
+			"b": map[string]interface{}{"c": 1},
+		},
+	}
+	if _, err := NewEncoder(WithMaxDepth(3)).Values(input); err == nil {
+		t.Errorf("expected Values() to return an error when exceeding max depth")
+	}
+	testEncoderValue(t, NewEncoder(WithMaxDepth(4)), input, url.Values{"a[b][c]": {"1"}})
+}
+
+func TestEncoder_Concurrent(t *testing.T) {
+	input := struct{ T time.Time }{time.Date(2022, 02, 11, 16, 39, 02, 0, time.UTC)}
+	date := NewEncoder(WithTimeFormat("2006-01-02"))
+	clock := NewEncoder(WithTimeFormat("15:04:05"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			testEncoderValue(t, date, input, url.Values{"T": {"2022-02-11"}})
+		}()
+		go func() {
+			defer wg.Done()
+			testEncoderValue(t, clock, input, url.Values{"T": {"16:39:02"}})
+		}()
+	}
+	wg.Wait()
+}
+
+func TestDecoder_Options(t *testing.T) {
+	var s struct {
+		T time.Time `url:"t"`
+		A string    `url:"a" qs:"x"`
+	}
+	dec := NewDecoder(WithTimeFormat("2006-01-02"), WithTagKey("url"))
+	err := dec.Unmarshal(url.Values{"t": {"2022-02-11"}, "a": {"a"}, "x": {"x"}}, &s)
+	if err != nil {
+		t.Fatalf("Unmarshal returned error: %v", err)
+	}
+	if want := time.Date(2022, 02, 11, 0, 0, 0, 0, time.UTC); !s.T.Equal(want) {
+		t.Errorf("Unmarshal time = %v, want %v", s.T, want)
+	}
+	if s.A != "a" {
+		t.Errorf("Unmarshal A = %q, want %q", s.A, "a")
+	}
+}
@@ -0,0 +1,148 @@
+package query
+
+import (
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// valueSink 接收编码输出的参数
+type valueSink interface {
+	add(key, value string)
+	// ordered 是否需要保持输出顺序，为true时map按key排序后输出
+	ordered() bool
+}
+
+// valuesSink 输出到url.Values
+type valuesSink url.Values
+
+func (s valuesSink) add(key, value string) {
+	url.Values(s).Add(key, value)
+}
+
+func (s valuesSink) ordered() bool {
+	return false
+}
+
+// Param 单个参数
+type Param struct {
+	Key   string
+	Value string
+}
+
+// Ordered 按编码顺序排列的参数，结构体字段按声明顺序输出，map按key排序输出
+type Ordered []Param
+
+func (o *Ordered) add(key, value string) {
+	*o = append(*o, Param{key, value})
+}
+
+func (o *Ordered) ordered() bool {
+	return true
+}
+
+// Add 在末尾追加参数
+func (o *Ordered) Add(key, value string) {
+	o.add(key, value)
+}
+
+// Get 返回参数名对应的第一个值
+func (o Ordered) Get(key string) string {
+	for _, p := range o {
+		if p.Key == key {
+			return p.Value
+		}
+	}
+	return ""
+}
+
+// Values 转换为url.Values，同名参数的值保持原有顺序
+func (o Ordered) Values() url.Values {
+	values := make(url.Values, len(o))
+	for _, p := range o {
+		values.Add(p.Key, p.Value)
+	}
+	return values
+}
+
+// Encode 按参数顺序编码为 key=value&key=value 的格式，与url.Values.Encode不同，不对参数名排序
+func (o Ordered) Encode() string {
+	var buf strings.Builder
+	for i, p := range o {
+		if i > 0 {
+			buf.WriteByte('&')
+		}
+		buf.WriteString(url.QueryEscape(p.Key))
+		buf.WriteByte('=')
+		buf.WriteString(url.QueryEscape(p.Value))
+	}
+	return buf.String()
+}
+
+// EncodeOrdered 使用默认编码器对v进行编码，参数按结构体字段的声明顺序排列
+func EncodeOrdered(v interface{}) (string, error) {
+	return defaultEncoder.EncodeOrdered(v)
+}
+
+// EncodeOrdered 对v进行编码，参数按结构体字段的声明顺序排列
+func (e *QueryEncoder) EncodeOrdered(v interface{}) (string, error) {
+	o, err := e.Ordered(v)
+	if err != nil {
+		return "", err
+	}
+	return o.Encode(), nil
+}
+
+// Ordered 对v进行编码，返回按编码顺序排列的参数
+func (e *QueryEncoder) Ordered(v interface{}) (Ordered, error) {
+	var o Ordered
+	if err := e.encode(v, &o); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// addSorted 将url.Values按参数名排序后输出，用于自定义Encoder等无序的输出
+func addSorted(sink valueSink, values url.Values) {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	if sink.ordered() {
+		sort.Strings(keys)
+	}
+	for _, k := range keys {
+		for _, v := range values[k] {
+			sink.add(k, v)
+		}
+	}
+}
+
+// mapKey map的key及其格式化后的参数名
+type mapKey struct {
+	val  reflect.Value
+	name string
+}
+
+// sortMapKeys 对map的key排序，整数、布尔值按值排序，其余按参数名排序，
+// 指定了WithMapKeyOrder时使用自定义的比较函数
+func (o *options) sortMapKeys(keys []mapKey) {
+	sort.Slice(keys, func(i, j int) bool {
+		if o.mapKeyLess != nil {
+			return o.mapKeyLess(keys[i].name, keys[j].name)
+		}
+		a, b := keys[i].val, keys[j].val
+		if a.Kind() == b.Kind() {
+			switch a.Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				return a.Int() < b.Int()
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+				return a.Uint() < b.Uint()
+			case reflect.Bool:
+				return !a.Bool() && b.Bool()
+			}
+		}
+		return keys[i].name < keys[j].name
+	})
+}
@@ -0,0 +1,111 @@
+package query
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEncodeOrdered(t *testing.T) {
+	type Sub struct {
+		Z string `qs:"z"`
+		A string `qs:"a"`
+	}
+
+	tests := []struct {
+		input interface{}
+		want  string
+	}{
+		{
+			struct {
+				Z string `qs:"z"`
+				B int    `qs:"b"`
+				A bool   `qs:"a"`
+			}{"z", 1, true},
+			"z=z&b=1&a=true",
+		},
+		{
+			struct {
+				Sort string   `qs:"sort"`
+				Sub  Sub      `qs:"sub"`
+				IDs  []int    `qs:"ids"`
+				Tags []string `qs:"tags,comma"`
+			}{"desc", Sub{"1", "2"}, []int{3, 1}, []string{"b", "a"}},
+			"sort=desc&sub%5Bz%5D=1&sub%5Ba%5D=2&ids%5B0%5D=3&ids%5B1%5D=1&tags=b%2Ca",
+		},
+		{
+			map[string]int{"c": 3, "a": 1, "b": 2},
+			"a=1&b=2&c=3",
+		},
+		{
+			map[int]string{10: "x", 2: "y", -1: "z"},
+			"-1=z&2=y&10=x",
+		},
+		{
+			struct {
+				Q string                 `qs:"q"`
+				V customEncodedStrings   `qs:"v"`
+				M map[string]interface{} `qs:"m"`
+			}{"a b", customEncodedStrings{"x", "y"}, map[string]interface{}{"k2": 2, "k1": []int{1}}},
+			"q=a+b&v.0=x&v.1=y&m%5Bk1%5D%5B0%5D=1&m%5Bk2%5D=2",
+		},
+		{
+			nil,
+			"",
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := EncodeOrdered(tt.input)
+		if err != nil {
+			t.Errorf("EncodeOrdered(%#v) returned error: %v", tt.input, err)
+		}
+		if got != tt.want {
+			t.Errorf("EncodeOrdered(%#v) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+
+	if _, err := EncodeOrdered(""); err == nil {
+		t.Errorf("expected EncodeOrdered() to return an error on invalid input")
+	}
+}
+
+func TestEncoder_MapKeyOrder(t *testing.T) {
+	enc := NewEncoder(WithMapKeyOrder(func(a, b string) bool { return a > b }))
+	got, err := enc.EncodeOrdered(map[string]int{"a": 1, "c": 3, "b": 2})
+	if err != nil {
+		t.Fatalf("EncodeOrdered returned error: %v", err)
+	}
+	if want := "c=3&b=2&a=1"; got != want {
+		t.Errorf("EncodeOrdered = %q, want %q", got, want)
+	}
+}
+
+func TestOrdered(t *testing.T) {
+	o, err := NewEncoder().Ordered(struct {
+		B []int `qs:"b,repeat"`
+		A string
+	}{[]int{2, 1}, "a"})
+	if err != nil {
+		t.Fatalf("Ordered returned error: %v", err)
+	}
+	o.Add("sig", "x")
+
+	want := Ordered{{"b", "2"}, {"b", "1"}, {"A", "a"}, {"sig", "x"}}
+	if diff := cmp.Diff(want, o); diff != "" {
+		t.Errorf("Ordered mismatch:\n%s", diff)
+	}
+	if diff := cmp.Diff(url.Values{"b": {"2", "1"}, "A": {"a"}, "sig": {"x"}}, o.Values()); diff != "" {
+		t.Errorf("Ordered.Values mismatch:\n%s", diff)
+	}
+	if got := o.Get("b"); got != "2" {
+		t.Errorf("Ordered.Get(b) = %q, want %q", got, "2")
+	}
+	if got := o.Get("c"); got != "" {
+		t.Errorf("Ordered.Get(c) = %q, want empty", got)
+	}
+	if got, want := o.Encode(), "b=2&b=1&A=a&sig=x"; got != want {
+		t.Errorf("Ordered.Encode = %q, want %q", got, want)
+	}
+}
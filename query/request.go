@@ -0,0 +1,23 @@
+package query
+
+import (
+	"errors"
+	"net/http"
+)
+
+// BindRequest 使用默认解码器将请求的参数解码到dst中
+func BindRequest(r *http.Request, dst interface{}) error {
+	return defaultDecoder.BindRequest(r, dst)
+}
+
+// BindRequest 将请求的URL参数及application/x-www-form-urlencoded格式的请求体解码到dst中，
+// 请求体只在POST、PUT、PATCH请求中解析，同名参数请求体中的值在前，单值字段优先使用请求体中的值
+func (d *QueryDecoder) BindRequest(r *http.Request, dst interface{}) error {
+	if r == nil {
+		return errors.New("bind request: nil *http.Request")
+	}
+	if err := r.ParseForm(); err != nil {
+		return err
+	}
+	return d.Unmarshal(r.Form, dst)
+}
@@ -0,0 +1,103 @@
+package query
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBindRequest(t *testing.T) {
+	type Filter struct {
+		Name string `qs:"name"`
+		Tags []int  `qs:"tags"`
+	}
+	type Search struct {
+		Q      string  `qs:"q"`
+		Page   int     `qs:"page"`
+		Filter *Filter `qs:"filter"`
+	}
+
+	form := "application/x-www-form-urlencoded"
+	tests := []struct {
+		method      string
+		target      string
+		contentType string
+		body        string
+		want        Search
+	}{
+		{
+			http.MethodGet,
+			"/search?q=go&page=2&filter%5Bname%5D=a&filter%5Btags%5D%5B0%5D=1&filter%5Btags%5D%5B1%5D=2",
+			"", "",
+			Search{Q: "go", Page: 2, Filter: &Filter{Name: "a", Tags: []int{1, 2}}},
+		},
+		{
+			http.MethodPost,
+			"/search",
+			form, "q=go&filter[tags][]=3&filter[tags][]=4",
+			Search{Q: "go", Filter: &Filter{Tags: []int{3, 4}}},
+		},
+		{
+			// 请求体中的值优先
+			http.MethodPut,
+			"/search?q=url&page=3",
+			form + "; charset=utf-8", "q=body",
+			Search{Q: "body", Page: 3},
+		},
+		{
+			// 非表单格式的请求体不解析
+			http.MethodPost,
+			"/search?page=1",
+			"application/json", `{"q":"json"}`,
+			Search{Page: 1},
+		},
+		{
+			// GET请求不解析请求体
+			http.MethodGet,
+			"/search?page=1",
+			form, "q=body",
+			Search{Page: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+		if tt.contentType != "" {
+			r.Header.Set("Content-Type", tt.contentType)
+		}
+		var got Search
+		if err := BindRequest(r, &got); err != nil {
+			t.Errorf("BindRequest(%s %s) returned error: %v", tt.method, tt.target, err)
+		}
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("BindRequest(%s %s) mismatch:\n%s", tt.method, tt.target, diff)
+		}
+	}
+}
+
+func TestBindRequest_Errors(t *testing.T) {
+	var s struct{ Page int }
+
+	if err := BindRequest(nil, &s); err == nil {
+		t.Errorf("BindRequest(nil) did not return error")
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("Page=%zz"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if err := BindRequest(r, &s); err == nil {
+		t.Errorf("BindRequest with malformed body did not return error")
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/?Page=x", nil)
+	if err := BindRequest(r, &s); err == nil {
+		t.Errorf("BindRequest with invalid int did not return error")
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/?Page=1", nil)
+	if err := BindRequest(r, s); err == nil {
+		t.Errorf("BindRequest with non-pointer did not return error")
+	}
+}
@@ -0,0 +1,150 @@
+package query
+
+import (
+	"encoding"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// stdCodec 标准库类型的编解码方法，这些类型作为单个值输出，而不是按结构体、整数处理，
+// 实现了encoding.TextMarshaler的类型如net.IP、netip.Addr、netip.Prefix不需要在此注册
+type stdCodec struct {
+	// format 输出值的字符串形式，val不一定可以取地址
+	format func(scope ScopeOptions, val reflect.Value) (string, error)
+	// parse 解析字符串，返回指向新值的指针
+	parse func(scope ScopeOptions, s string) (reflect.Value, error)
+}
+
+// stdCodecs 标准库类型对应的编解码方法
+var stdCodecs = map[reflect.Type]*stdCodec{
+	durationType: {formatDuration, parseDuration},
+	reflect.TypeOf(url.URL{}): {formatString, func(_ ScopeOptions, s string) (reflect.Value, error) {
+		u, err := url.Parse(s)
+		return reflect.ValueOf(u), err
+	}},
+	reflect.TypeOf(net.IPNet{}): {formatString, func(_ ScopeOptions, s string) (reflect.Value, error) {
+		n, err := parseIPNet(s)
+		return reflect.ValueOf(n), err
+	}},
+	reflect.TypeOf(time.Location{}): {formatString, func(_ ScopeOptions, s string) (reflect.Value, error) {
+		loc, err := time.LoadLocation(s)
+		return reflect.ValueOf(loc), err
+	}},
+	reflect.TypeOf(regexp.Regexp{}): {formatString, func(_ ScopeOptions, s string) (reflect.Value, error) {
+		re, err := regexp.Compile(s)
+		return reflect.ValueOf(re), err
+	}},
+}
+
+// parseIPNet 解析CIDR格式的网络，与net.ParseCIDR不同，保留其中的主机地址，
+// IPv4地址与掩码同为4字节
+func parseIPNet(s string) (*net.IPNet, error) {
+	ip, n, err := net.ParseCIDR(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(n.IP) == net.IPv4len {
+		ip = ip.To4()
+	}
+	return &net.IPNet{IP: ip, Mask: n.Mask}, nil
+}
+
+func init() {
+	// 指针实现了encoding.TextMarshaler，值不可取地址时也需要作为单个值输出
+	for _, v := range []interface{}{new(big.Int), new(big.Float), new(big.Rat)} {
+		t := reflect.TypeOf(v).Elem()
+		stdCodecs[t] = &stdCodec{formatText, func(_ ScopeOptions, s string) (reflect.Value, error) {
+			p := reflect.New(t)
+			return p, p.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+		}}
+	}
+}
+
+// durationFormatOptions 标签选项与时长单位的对应关系，未指定时使用time.Duration.String的格式
+var durationFormatOptions = map[string]time.Duration{
+	"seconds": time.Second,
+	"ms":      time.Millisecond,
+}
+
+// addrOf 返回指向val的指针，val不可取地址时指向其副本
+func addrOf(val reflect.Value) reflect.Value {
+	if val.CanAddr() {
+		return val.Addr()
+	}
+	p := reflect.New(val.Type())
+	p.Elem().Set(val)
+	return p
+}
+
+// formatString 使用指针上的String方法输出
+func formatString(_ ScopeOptions, val reflect.Value) (string, error) {
+	return addrOf(val).Interface().(fmt.Stringer).String(), nil
+}
+
+// formatText 使用指针上的MarshalText方法输出
+func formatText(_ ScopeOptions, val reflect.Value) (string, error) {
+	text, err := addrOf(val).Interface().(encoding.TextMarshaler).MarshalText()
+	return string(text), err
+}
+
+// durationUnitOf 返回当前域使用的时长单位，0表示使用time.Duration.String的格式
+func durationUnitOf(scope ScopeOptions) time.Duration {
+	if scope.format != nil {
+		return scope.format.duration
+	}
+	return 0
+}
+
+// formatDuration 按单位输出时长，如 1.5 秒，未指定单位时输出 1.5s
+func formatDuration(scope ScopeOptions, val reflect.Value) (string, error) {
+	d := time.Duration(val.Int())
+	unit := durationUnitOf(scope)
+	if unit == 0 {
+		return d.String(), nil
+	}
+	return strconv.FormatFloat(float64(d)/float64(unit), 'f', -1, 64), nil
+}
+
+// parseDuration 按单位解析时长，未指定单位时也接受以纳秒为单位的整数
+func parseDuration(scope ScopeOptions, s string) (reflect.Value, error) {
+	var d time.Duration
+	if unit := durationUnitOf(scope); unit != 0 {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		d = time.Duration(math.Round(f * float64(unit)))
+	} else if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		d = time.Duration(n)
+	} else if d, err = time.ParseDuration(s); err != nil {
+		return reflect.Value{}, err
+	}
+	return reflect.ValueOf(&d), nil
+}
+
+// stdDecode 将单个值解码为标准库类型，val为指针时直接指向解析结果，如time.LoadLocation返回的*time.Location
+func (d *QueryDecoder) stdDecode(scope ScopeOptions, node *keyNode, val reflect.Value, c *stdCodec) error {
+	s, ok := node.value()
+	if !ok {
+		return nil
+	}
+	p, err := c.parse(scope, s)
+	if err != nil {
+		return fmt.Errorf("invalid value of %s: %v", scope.Scope, err)
+	}
+	if val.Kind() == reflect.Ptr {
+		val.Set(p)
+	} else {
+		val.Set(p.Elem())
+	}
+	return nil
+}
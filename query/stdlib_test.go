@@ -0,0 +1,211 @@
+package query
+
+import (
+	"math/big"
+	"net"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValues_StdlibTypes(t *testing.T) {
+	_, ipnet, _ := net.ParseCIDR("10.0.0.0/8")
+	u, _ := url.Parse("https://example.com/a?b=c")
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skipf("time zone data not available: %v", err)
+	}
+
+	tests := []struct {
+		input interface{}
+		want  url.Values
+	}{
+		{
+			struct {
+				IP    net.IP
+				Net   net.IPNet
+				PNet  *net.IPNet
+				URL   url.URL
+				PURL  *url.URL
+				Loc   *time.Location
+				Re    *regexp.Regexp
+				Int   big.Int
+				Float *big.Float
+				Rat   big.Rat
+			}{
+				IP:    net.ParseIP("192.168.0.1"),
+				Net:   *ipnet,
+				PNet:  ipnet,
+				URL:   *u,
+				PURL:  u,
+				Loc:   shanghai,
+				Re:    regexp.MustCompile(`^a+$`),
+				Int:   *big.NewInt(-12345678901234),
+				Float: big.NewFloat(1.5),
+				Rat:   *big.NewRat(1, 3),
+			},
+			url.Values{
+				"IP":    {"192.168.0.1"},
+				"Net":   {"10.0.0.0/8"},
+				"PNet":  {"10.0.0.0/8"},
+				"URL":   {"https://example.com/a?b=c"},
+				"PURL":  {"https://example.com/a?b=c"},
+				"Loc":   {"Asia/Shanghai"},
+				"Re":    {"^a+$"},
+				"Int":   {"-12345678901234"},
+				"Float": {"1.5"},
+				"Rat":   {"1/3"},
+			},
+		},
+		{
+			struct {
+				D   time.Duration
+				S   time.Duration   `qs:"s,seconds"`
+				Ms  time.Duration   `qs:"ms,ms"`
+				Str time.Duration   `qs:"str,string"`
+				L   []time.Duration `qs:"l,comma,ms"`
+				M   map[string]big.Int
+			}{
+				D:   90 * time.Second,
+				S:   1500 * time.Millisecond,
+				Ms:  2 * time.Second,
+				Str: time.Minute,
+				L:   []time.Duration{time.Millisecond, 1500 * time.Microsecond},
+				M:   map[string]big.Int{"n": *big.NewInt(7)},
+			},
+			url.Values{
+				"D":    {"1m30s"},
+				"s":    {"1.5"},
+				"ms":   {"2000"},
+				"str":  {"1m0s"},
+				"l":    {"1,1.5"},
+				"M[n]": {"7"},
+			},
+		},
+		{
+			// nil的net.IP与nil切片相同，不输出
+			struct {
+				IP  net.IP
+				PIP *net.IP
+			}{PIP: new(net.IP)},
+			url.Values{},
+		},
+	}
+
+	for _, tt := range tests {
+		testValue(t, tt.input, tt.want)
+	}
+}
+
+func TestUnmarshal_StdlibTypes(t *testing.T) {
+	type Config struct {
+		IP      net.IP
+		Net     net.IPNet
+		PNet    *net.IPNet
+		URL     url.URL
+		PURL    *url.URL
+		Loc     *time.Location
+		Re      *regexp.Regexp
+		Int     big.Int
+		Float   *big.Float
+		Rat     big.Rat
+		D       time.Duration
+		Nanos   time.Duration
+		S       time.Duration   `qs:"s,seconds"`
+		Ms      time.Duration   `qs:"ms,ms"`
+		L       []time.Duration `qs:"l,comma"`
+		Missing *url.URL
+	}
+	values := url.Values{
+		"IP":    {"::1"},
+		"Net":   {"10.1.2.3/8"},
+		"PNet":  {"192.168.0.0/16"},
+		"URL":   {"https://example.com/a?b=c"},
+		"PURL":  {"/path"},
+		"Loc":   {"UTC"},
+		"Re":    {"^a+$"},
+		"Int":   {"12345678901234567890"},
+		"Float": {"2.25"},
+		"Rat":   {"3/6"},
+		"D":     {"1h2m"},
+		"Nanos": {"1000"},
+		"s":     {"1.5"},
+		"ms":    {"250"},
+		"l":     {"1s,2ms"},
+	}
+
+	var got Config
+	if err := Unmarshal(values, &got); err != nil {
+		t.Fatalf("Unmarshal(%v) returned error: %v", values, err)
+	}
+	checks := []struct {
+		name      string
+		got, want interface{}
+	}{
+		{"IP", got.IP.String(), "::1"},
+		{"Net", got.Net.String(), "10.1.2.3/8"},
+		{"PNet", got.PNet.String(), "192.168.0.0/16"},
+		{"URL", got.URL.Host, "example.com"},
+		{"PURL", got.PURL.Path, "/path"},
+		{"Loc", got.Loc == time.UTC, true},
+		{"Re", got.Re.MatchString("aaa"), true},
+		{"Int", got.Int.String(), "12345678901234567890"},
+		{"Float", got.Float.String(), "2.25"},
+		{"Rat", got.Rat.String(), "1/2"},
+		{"D", got.D, time.Hour + 2*time.Minute},
+		{"Nanos", got.Nanos, time.Microsecond},
+		{"S", got.S, 1500 * time.Millisecond},
+		{"Ms", got.Ms, 250 * time.Millisecond},
+		{"L", len(got.L) == 2 && got.L[0] == time.Second && got.L[1] == 2*time.Millisecond, true},
+		{"Missing", got.Missing == nil, true},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("Unmarshal(%v).%s = %v, want %v", values, c.name, c.got, c.want)
+		}
+	}
+
+	for _, values := range []url.Values{
+		{"Net": {"10.0.0.0"}},
+		{"PURL": {"%zz"}},
+		{"Loc": {"Nowhere/Atlantis"}},
+		{"Re": {"("}},
+		{"Int": {"1.5"}},
+		{"D": {"soon"}},
+		{"s": {"1s"}},
+	} {
+		if err := Unmarshal(values, &got); err == nil {
+			t.Errorf("Unmarshal(%v) did not return error", values)
+		}
+	}
+}
+
+func TestUnmarshal_IPNetRoundTrip(t *testing.T) {
+	type Config struct {
+		Net  net.IPNet
+		PNet *net.IPNet
+	}
+	// 主机地址不为网络地址
+	input := Config{
+		Net:  net.IPNet{IP: net.IPv4(10, 1, 2, 3).To4(), Mask: net.CIDRMask(8, 32)},
+		PNet: &net.IPNet{IP: net.ParseIP("2001:db8::1"), Mask: net.CIDRMask(32, 128)},
+	}
+	values, err := Values(input)
+	if err != nil {
+		t.Fatalf("Values(%v) returned error: %v", input, err)
+	}
+	if got, want := values.Get("Net"), "10.1.2.3/8"; got != want {
+		t.Errorf("Values(%v) Net = %q, want %q", input, got, want)
+	}
+
+	var got Config
+	if err := Unmarshal(values, &got); err != nil {
+		t.Fatalf("Unmarshal(%v) returned error: %v", values, err)
+	}
+	if diff := cmp.Diff(input, got); diff != "" {
+		t.Errorf("Unmarshal(%v) mismatch:\n%s", values, diff)
+	}
+}
@@ -0,0 +1,92 @@
+package query
+
+import (
+	"io"
+)
+
+// flushSize EncodeTo缓冲区达到该大小时写入io.Writer
+const flushSize = 4096
+
+// appendSink 将参数直接转义后追加到buf，不经过url.Values，参数按编码顺序输出
+type appendSink struct {
+	buf []byte
+	// 已输出的参数个数，用于添加分隔符
+	n int
+	// 不为nil时缓冲区超过flushSize后写入w
+	w   io.Writer
+	err error
+}
+
+func (s *appendSink) add(key, value string) {
+	if s.n > 0 {
+		s.buf = append(s.buf, '&')
+	}
+	s.n++
+	s.buf = appendQueryEscape(s.buf, key)
+	s.buf = append(s.buf, '=')
+	s.buf = appendQueryEscape(s.buf, value)
+	if s.w != nil && len(s.buf) >= flushSize {
+		s.flush()
+	}
+}
+
+func (s *appendSink) ordered() bool {
+	return true
+}
+
+// flush 将缓冲区写入w，写入出错后不再写入
+func (s *appendSink) flush() {
+	if s.err == nil && len(s.buf) > 0 {
+		_, s.err = s.w.Write(s.buf)
+	}
+	s.buf = s.buf[:0]
+}
+
+// EncodeTo 使用默认编码器对v进行编码，并写入w
+func EncodeTo(w io.Writer, v interface{}) error {
+	return defaultEncoder.EncodeTo(w, v)
+}
+
+// EncodeTo 对v进行编码，按编码顺序将 key=value&key=value 格式的结果写入w，
+// 编码过程中分块写入，不生成中间的url.Values，编码出错时w中可能已写入部分参数
+func (e *QueryEncoder) EncodeTo(w io.Writer, v interface{}) error {
+	s := &appendSink{buf: make([]byte, 0, flushSize), w: w}
+	if err := e.encode(v, s); err != nil {
+		return err
+	}
+	s.flush()
+	return s.err
+}
+
+// AppendQuery 使用默认编码器对v进行编码，并追加到dst
+func AppendQuery(dst []byte, v interface{}) ([]byte, error) {
+	return defaultEncoder.AppendQuery(dst, v)
+}
+
+// AppendQuery 对v进行编码，按编码顺序将 key=value&key=value 格式的结果追加到dst并返回，
+// 不在dst与结果之间添加分隔符，编码出错时返回原dst
+func (e *QueryEncoder) AppendQuery(dst []byte, v interface{}) ([]byte, error) {
+	s := &appendSink{buf: dst}
+	if err := e.encode(v, s); err != nil {
+		return dst, err
+	}
+	return s.buf, nil
+}
+
+// appendQueryEscape 按url.QueryEscape的规则转义s并追加到dst
+func appendQueryEscape(dst []byte, s string) []byte {
+	const hex = "0123456789ABCDEF"
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			dst = append(dst, c)
+		case c == ' ':
+			dst = append(dst, '+')
+		default:
+			dst = append(dst, '%', hex[c>>4], hex[c&15])
+		}
+	}
+	return dst
+}
@@ -0,0 +1,104 @@
+package query
+
+import (
+	"bytes"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestEncodeTo(t *testing.T) {
+	type Sub struct {
+		Z string `qs:"z"`
+		A string `qs:"a"`
+	}
+
+	tests := []interface{}{
+		struct {
+			Z string `qs:"z"`
+			B int    `qs:"b"`
+			A bool   `qs:"a"`
+		}{"z", 1, true},
+		&struct {
+			Sort string         `qs:"sort"`
+			Sub  Sub            `qs:"sub"`
+			IDs  []int          `qs:"ids"`
+			Tags []string       `qs:"tags,comma"`
+			M    map[string]int `qs:"m"`
+		}{"a b&c", Sub{"1", "2"}, []int{3, 1}, []string{"b", "a"}, map[string]int{"y": 2, "x": 1}},
+		map[string]int{"c": 3, "a": 1, "b": 2},
+		struct {
+			Long []string `qs:"l,repeat"`
+		}{strings.Split(strings.Repeat("ä ü/", 2000), "/")},
+		nil,
+	}
+
+	for _, input := range tests {
+		want, err := EncodeOrdered(input)
+		if err != nil {
+			t.Fatalf("EncodeOrdered(%v) returned error: %v", input, err)
+		}
+
+		var buf bytes.Buffer
+		if err := EncodeTo(&buf, input); err != nil {
+			t.Errorf("EncodeTo(%v) returned error: %v", input, err)
+		}
+		if got := buf.String(); got != want {
+			t.Errorf("EncodeTo(%v) = %q, want %q", input, got, want)
+		}
+
+		got, err := AppendQuery([]byte("/path?"), input)
+		if err != nil {
+			t.Errorf("AppendQuery(%v) returned error: %v", input, err)
+		}
+		if string(got) != "/path?"+want {
+			t.Errorf("AppendQuery(%v) = %q, want %q", input, got, "/path?"+want)
+		}
+	}
+}
+
+func TestEncodeTo_Errors(t *testing.T) {
+	var buf bytes.Buffer
+	err := EncodeTo(&buf, 1)
+	if !errors.Is(err, ErrUnsupportedKind) {
+		t.Errorf("EncodeTo(1) returned error %v, want %v", err, ErrUnsupportedKind)
+	}
+
+	dst := []byte("a=1&")
+	got, err := AppendQuery(dst, struct {
+		A string
+		M map[[1]int]int
+	}{"a", map[[1]int]int{{1}: 1}})
+	if !errors.Is(err, ErrUnsupportedKind) {
+		t.Errorf("AppendQuery returned error %v, want %v", err, ErrUnsupportedKind)
+	}
+	if string(got) != "a=1&" {
+		t.Errorf("AppendQuery returned %q on error, want original dst", got)
+	}
+
+	werr := errors.New("write failed")
+	err = EncodeTo(failingWriter{werr}, struct{ A string }{"a"})
+	if err != werr {
+		t.Errorf("EncodeTo returned error %v, want %v", err, werr)
+	}
+}
+
+func TestAppendQueryEscape(t *testing.T) {
+	var b strings.Builder
+	for c := 0; c < 256; c++ {
+		b.WriteByte(byte(c))
+	}
+	s := b.String() + "日本"
+	if got, want := string(appendQueryEscape(nil, s)), url.QueryEscape(s); got != want {
+		t.Errorf("appendQueryEscape(%q) = %q, want %q", s, got, want)
+	}
+}
+
+type failingWriter struct {
+	err error
+}
+
+func (w failingWriter) Write(p []byte) (int, error) {
+	return 0, w.err
+}
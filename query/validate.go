@@ -0,0 +1,234 @@
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValidationError 解码时字段未通过标签中的校验规则
+type ValidationError struct {
+	// Key 参数名，如 filter[page]
+	Key string
+	// Field Go字段路径，如 Filter.Page
+	Field string
+	// Rule 未通过的规则，如 required、min=1
+	Rule string
+	// Err 具体原因
+	Err error
+}
+
+func (e *ValidationError) Error() string {
+	msg := "query: validate " + e.Key
+	if e.Field != "" {
+		msg += " (field " + e.Field + ")"
+	}
+	return msg + ": " + e.Err.Error()
+}
+
+// Unwrap 返回具体原因
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// fieldRules 字段标签中的校验规则，仅在解码时检查
+type fieldRules struct {
+	required bool
+	// 数值的范围，字符串、切片、数组、map为长度的范围
+	min, max       *float64
+	minTag, maxTag string
+	length         *int
+	lenTag         string
+	oneOf          []string
+	oneOfTag       string
+	pattern        *regexp.Regexp
+	patternTag     string
+}
+
+// parseFieldRules 解析标签选项中的校验规则，没有校验规则时返回nil，
+// pattern=需为最后一个选项，其后的逗号属于正则表达式，其后出现其他选项时返回错误
+func parseFieldRules(opts tagOptions) (*fieldRules, error) {
+	var r fieldRules
+	found := false
+	for i, opt := range opts {
+		switch {
+		case opt == "required":
+			r.required = true
+		case strings.HasPrefix(opt, "min="), strings.HasPrefix(opt, "max="):
+			n, err := strconv.ParseFloat(opt[4:], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid rule %q: %v", opt, err)
+			}
+			if opt[:3] == "min" {
+				r.min, r.minTag = &n, opt
+			} else {
+				r.max, r.maxTag = &n, opt
+			}
+		case strings.HasPrefix(opt, "len="):
+			n, err := strconv.Atoi(opt[4:])
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid rule %q", opt)
+			}
+			r.length, r.lenTag = &n, opt
+		case strings.HasPrefix(opt, "oneof="):
+			r.oneOf, r.oneOfTag = strings.Split(opt[len("oneof="):], "|"), opt
+		case strings.HasPrefix(opt, "pattern="):
+			for _, rest := range opts[i+1:] {
+				if isTagOption(rest) {
+					return nil, fmt.Errorf("option %q after pattern=, pattern= must be the last option", rest)
+				}
+			}
+			expr := strings.Join(append([]string{opt[len("pattern="):]}, opts[i+1:]...), ",")
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid rule %q: %v", "pattern="+expr, err)
+			}
+			r.pattern, r.patternTag = re, "pattern="+expr
+			return &r, nil
+		default:
+			continue
+		}
+		found = true
+	}
+	if !found {
+		return nil, nil
+	}
+	return &r, nil
+}
+
+// isTagOption 是否为标签中的忽略、格式、校验或default=选项
+func isTagOption(opt string) bool {
+	switch opt {
+	case "omitempty", "omitempty=deep", "omitzero", "omitnil", "required":
+		return true
+	}
+	for _, prefix := range []string{"min=", "max=", "len=", "oneof=", "pattern=", "default="} {
+		if strings.HasPrefix(opt, prefix) {
+			return true
+		}
+	}
+	return parseFieldFormat(tagOptions{opt}) != nil
+}
+
+// validationError 返回当前字段的ValidationError
+func validationError(scope ScopeOptions, rule, format string, args ...interface{}) error {
+	return &ValidationError{Key: scope.Scope, Field: scope.path.String(), Rule: rule, Err: fmt.Errorf(format, args...)}
+}
+
+// present 参数是否存在且不为空值
+func (n *keyNode) present() bool {
+	if len(n.children) > 0 {
+		return true
+	}
+	for _, s := range n.values {
+		if s != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// validate 检查解码后的字段值，nil指针不检查
+func (r *fieldRules) validate(scope ScopeOptions, val reflect.Value) error {
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+	if r.length != nil {
+		n, ok := lengthOf(val)
+		if !ok {
+			return fmt.Errorf("%s: rule %q is not supported for type %v", scope.Scope, r.lenTag, val.Type())
+		}
+		if n != *r.length {
+			return validationError(scope, r.lenTag, "length must be %d, got %d", *r.length, n)
+		}
+	}
+	if r.min != nil || r.max != nil {
+		if err := r.validateRange(scope, val); err != nil {
+			return err
+		}
+	}
+	if r.oneOf == nil && r.pattern == nil {
+		return nil
+	}
+	for _, s := range elementStrings(val) {
+		if r.oneOf != nil && !containsString(r.oneOf, s) {
+			return validationError(scope, r.oneOfTag, "%q must be one of %s", s, strings.Join(r.oneOf, "|"))
+		}
+		if r.pattern != nil && !r.pattern.MatchString(s) {
+			return validationError(scope, r.patternTag, "%q does not match pattern %s", s, r.pattern)
+		}
+	}
+	return nil
+}
+
+// validateRange 检查min、max，数值比较值的大小，字符串等比较长度
+func (r *fieldRules) validateRange(scope ScopeOptions, val reflect.Value) error {
+	what := "value"
+	var n float64
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = float64(val.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n = float64(val.Uint())
+	case reflect.Float32, reflect.Float64:
+		n = val.Float()
+	default:
+		l, ok := lengthOf(val)
+		if !ok {
+			tag := r.minTag
+			if tag == "" {
+				tag = r.maxTag
+			}
+			return fmt.Errorf("%s: rule %q is not supported for type %v", scope.Scope, tag, val.Type())
+		}
+		what, n = "length", float64(l)
+	}
+	if r.min != nil && n < *r.min {
+		return validationError(scope, r.minTag, "%s must be at least %v, got %v", what, *r.min, n)
+	}
+	if r.max != nil && n > *r.max {
+		return validationError(scope, r.maxTag, "%s must be at most %v, got %v", what, *r.max, n)
+	}
+	return nil
+}
+
+// lengthOf 字符串的字符数，切片、数组、map的元素个数
+func lengthOf(val reflect.Value) (int, bool) {
+	switch val.Kind() {
+	case reflect.String:
+		return utf8.RuneCountInString(val.String()), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return val.Len(), true
+	}
+	return 0, false
+}
+
+// elementStrings 值的字符串形式，切片、数组返回每个元素的字符串形式
+func elementStrings(val reflect.Value) []string {
+	if val.Kind() != reflect.Slice && val.Kind() != reflect.Array {
+		return []string{fmt.Sprint(val.Interface())}
+	}
+	var ss []string
+	for i := 0; i < val.Len(); i++ {
+		if elem := reflect.Indirect(val.Index(i)); elem.IsValid() {
+			ss = append(ss, elementStrings(elem)...)
+		}
+	}
+	return ss
+}
+
+// containsString ss中是否包含s
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
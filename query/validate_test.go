@@ -0,0 +1,153 @@
+package query
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type validated struct {
+	Page  int      `qs:"page,required,min=1"`
+	Size  *uint    `qs:"size,max=100"`
+	Sort  string   `qs:"sort,oneof=asc|desc"`
+	Q     string   `qs:"q,max=5,pattern=^[a-z]{0,3}$"`
+	Code  string   `qs:"code,len=2"`
+	Tags  []string `qs:"tags,comma,min=1,max=2,oneof=a|b|c"`
+	Score float64  `qs:"score,min=-1.5,max=1.5"`
+	Sub   struct {
+		Name string `qs:"name,required"`
+	} `qs:"sub,omitempty"`
+}
+
+func TestUnmarshal_Validation(t *testing.T) {
+	tests := []struct {
+		query string
+		want  *ValidationError
+	}{
+		{"page=1&sub[name]=x", nil},
+		{"page=2&size=100&sort=desc&q=abc&code=ab&tags=a,c&score=1.5&sub[name]=x", nil},
+		{"sub[name]=x", &ValidationError{Key: "page", Field: "Page", Rule: "required"}},
+		{"page=&sub[name]=x", &ValidationError{Key: "page", Field: "Page", Rule: "required"}},
+		{"page=0&sub[name]=x", &ValidationError{Key: "page", Field: "Page", Rule: "min=1"}},
+		{"page=1&size=101&sub[name]=x", &ValidationError{Key: "size", Field: "Size", Rule: "max=100"}},
+		{"page=1&sort=up&sub[name]=x", &ValidationError{Key: "sort", Field: "Sort", Rule: "oneof=asc|desc"}},
+		{"page=1&q=abcdef&sub[name]=x", &ValidationError{Key: "q", Field: "Q", Rule: "max=5"}},
+		{"page=1&q=ab1&sub[name]=x", &ValidationError{Key: "q", Field: "Q", Rule: "pattern=^[a-z]{0,3}$"}},
+		{"page=1&code=abc&sub[name]=x", &ValidationError{Key: "code", Field: "Code", Rule: "len=2"}},
+		{"page=1&tags=a,b,c&sub[name]=x", &ValidationError{Key: "tags", Field: "Tags", Rule: "max=2"}},
+		{"page=1&tags=a,d&sub[name]=x", &ValidationError{Key: "tags", Field: "Tags", Rule: "oneof=a|b|c"}},
+		{"page=1&score=-2&sub[name]=x", &ValidationError{Key: "score", Field: "Score", Rule: "min=-1.5"}},
+		{"page=1&sub[other]=x", &ValidationError{Key: "sub[name]", Field: "Sub.Name", Rule: "required"}},
+		// 嵌套结构体没有对应参数时也检查required
+		{"page=1", &ValidationError{Key: "sub[name]", Field: "Sub.Name", Rule: "required"}},
+	}
+
+	for _, tt := range tests {
+		values, _ := url.ParseQuery(tt.query)
+		var v validated
+		err := Unmarshal(values, &v)
+		if tt.want == nil {
+			if err != nil {
+				t.Errorf("Unmarshal(%q) returned error: %v", tt.query, err)
+			}
+			continue
+		}
+		var ve *ValidationError
+		if !errors.As(err, &ve) {
+			t.Errorf("Unmarshal(%q) returned error %v, want *ValidationError", tt.query, err)
+			continue
+		}
+		if diff := cmp.Diff(tt.want, ve, cmp.FilterPath(func(p cmp.Path) bool {
+			return p.Last().String() == ".Err"
+		}, cmp.Ignore())); diff != "" {
+			t.Errorf("Unmarshal(%q) error mismatch:\n%s", tt.query, diff)
+		}
+		if !strings.Contains(err.Error(), tt.want.Key) {
+			t.Errorf("Unmarshal(%q) error %q does not contain key %q", tt.query, err, tt.want.Key)
+		}
+	}
+}
+
+func TestUnmarshal_NestedRequired(t *testing.T) {
+	type Filter struct {
+		Page int `qs:"page,required"`
+	}
+	var v struct {
+		F Filter  `qs:"filter"`
+		P *Filter `qs:"p"`
+	}
+	tests := []struct {
+		values url.Values
+		key    string
+	}{
+		{url.Values{"x": {"1"}}, "filter[page]"},
+		{url.Values{"filter[page]": {"1"}}, "p[page]"},
+	}
+	for _, tt := range tests {
+		var ve *ValidationError
+		if err := Unmarshal(tt.values, &v); !errors.As(err, &ve) || ve.Key != tt.key || ve.Rule != "required" {
+			t.Errorf("Unmarshal(%v) returned error %v, want required error for %s", tt.values, err, tt.key)
+		}
+	}
+	if err := Unmarshal(url.Values{"filter[page]": {"1"}, "p[page]": {"2"}}, &v); err != nil {
+		t.Errorf("Unmarshal returned error: %v", err)
+	}
+}
+
+func TestUnmarshal_ValidationMessage(t *testing.T) {
+	values := url.Values{"page": {"0"}}
+	var v struct {
+		Page int `qs:"page,min=1"`
+	}
+	err := Unmarshal(values, &v)
+	want := "query: validate page (field Page): value must be at least 1, got 0"
+	if err == nil || err.Error() != want {
+		t.Errorf("Unmarshal returned error %v, want %q", err, want)
+	}
+}
+
+func TestUnmarshal_InvalidRules(t *testing.T) {
+	values := url.Values{"a": {"1"}}
+	tests := []interface{}{
+		&struct {
+			A int `qs:"a,min=x"`
+		}{},
+		&struct {
+			A string `qs:"a,pattern=["`
+		}{},
+		&struct {
+			A int `qs:"a,len=1"`
+		}{},
+		&struct {
+			A bool `qs:"a,max=1"`
+		}{},
+		// pattern=之后的选项会被当作正则表达式的一部分
+		&struct {
+			A string `qs:"a,pattern=^[a-z]+$,required"`
+		}{},
+		&struct {
+			A string `qs:"a,pattern=^[a-z]+$,omitempty"`
+		}{},
+	}
+	for _, v := range tests {
+		err := Unmarshal(values, v)
+		var ve *ValidationError
+		if err == nil || errors.As(err, &ve) {
+			t.Errorf("Unmarshal(%T) returned error %v, want rule error", v, err)
+		}
+	}
+
+	// 正则表达式中的逗号不是选项
+	var v struct {
+		A string `qs:"a,required,pattern=^[0-9]{1,3}(,[0-9]{3})*$"`
+	}
+	if err := Unmarshal(url.Values{"a": {"1,000"}}, &v); err != nil || v.A != "1,000" {
+		t.Errorf("Unmarshal with comma in pattern = %q, %v, want %q", v.A, err, "1,000")
+	}
+	if err := Unmarshal(url.Values{}, &v); err == nil {
+		t.Errorf("Unmarshal without required parameter did not return error")
+	}
+}
@@ -0,0 +1,66 @@
+package query
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"net/url"
+	"reflect"
+)
+
+var valuerType = reflect.TypeOf(new(driver.Valuer)).Elem()
+var scannerType = reflect.TypeOf(new(sql.Scanner)).Elem()
+
+// valuerOf 返回值或其指针实现的driver.Valuer
+func (ti *typeInfo) valuerOf(val reflect.Value) (driver.Valuer, bool) {
+	if ti.valuer {
+		return val.Interface().(driver.Valuer), true
+	}
+	if ti.ptrValuer && val.CanAddr() {
+		return val.Addr().Interface().(driver.Valuer), true
+	}
+	return nil, false
+}
+
+// isNullValuer 值是否实现了driver.Valuer且Value返回nil，如Valid为false的sql.NullString，
+// 这样的值与nil指针相同
+func isNullValuer(v reflect.Value, tagKey string) bool {
+	if v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		return false
+	}
+	m, ok := cachedTypeInfo(v.Type(), tagKey).valuerOf(v)
+	if !ok {
+		return false
+	}
+	dv, err := m.Value()
+	return err == nil && dv == nil
+}
+
+// isNullStruct 是否为sql.NullString等类型的结构：值字段及表示非NULL的Valid字段
+func isNullStruct(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || t.NumField() != 2 {
+		return false
+	}
+	v, valid := t.Field(0), t.Field(1)
+	return v.PkgPath == "" && valid.Name == "Valid" && valid.Type.Kind() == reflect.Bool
+}
+
+// scannerDecode 解码实现了sql.Scanner的类型，sql.NullString等类型按值字段解码并设置Valid，
+// 其他类型将字符串传给Scan
+func (d *QueryDecoder) scannerDecode(scope ScopeOptions, values url.Values, node *keyNode, val reflect.Value) error {
+	s, ok := node.value()
+	if !ok {
+		return nil
+	}
+	if isNullStruct(val.Type()) {
+		if err := d.valueDecode(scope, values, node, val.Field(0)); err != nil {
+			return err
+		}
+		val.Field(1).SetBool(true)
+		return nil
+	}
+	if err := val.Addr().Interface().(sql.Scanner).Scan(s); err != nil {
+		return fmt.Errorf("invalid value of %s: %v", scope.Scope, err)
+	}
+	return nil
+}
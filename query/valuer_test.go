@@ -0,0 +1,154 @@
+package query
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// money 以分为单位保存，通过driver.Valuer输出元
+type money int64
+
+func (m money) Value() (driver.Value, error) {
+	if m < 0 {
+		return nil, errors.New("negative amount")
+	}
+	return fmt.Sprintf("%d.%02d", m/100, m%100), nil
+}
+
+// csv 通过sql.Scanner解析逗号分隔的字符串
+type csv []string
+
+func (c *csv) Scan(src interface{}) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("unsupported type %T", src)
+	}
+	*c = strings.Split(s, ",")
+	return nil
+}
+
+func TestValues_Valuer(t *testing.T) {
+	created := time.Date(2022, 2, 11, 16, 39, 2, 0, time.UTC)
+	tests := []struct {
+		input interface{}
+		want  url.Values
+	}{
+		{
+			struct {
+				S  sql.NullString
+				I  sql.NullInt64
+				I3 sql.NullInt32   `qs:"i3"`
+				F  sql.NullFloat64 `qs:"f,prec=2"`
+				B  sql.NullBool    `qs:"b,int"`
+				T  sql.NullTime    `qs:"t,unix"`
+				P  *sql.NullString
+				M  money
+			}{
+				S:  sql.NullString{String: "foo", Valid: true},
+				I:  sql.NullInt64{Int64: 42, Valid: true},
+				I3: sql.NullInt32{Int32: 0, Valid: true},
+				F:  sql.NullFloat64{Float64: 1.5, Valid: true},
+				B:  sql.NullBool{Bool: true, Valid: true},
+				T:  sql.NullTime{Time: created, Valid: true},
+				P:  &sql.NullString{String: "bar", Valid: true},
+				M:  1234,
+			},
+			url.Values{
+				"S":  {"foo"},
+				"I":  {"42"},
+				"i3": {"0"},
+				"f":  {"1.50"},
+				"b":  {"1"},
+				"t":  {"1644597542"},
+				"P":  {"bar"},
+				"M":  {"12.34"},
+			},
+		},
+		{
+			// Valid为false时与nil相同
+			struct {
+				S sql.NullString
+				I sql.NullInt64    `qs:"i,omitempty"`
+				E sql.NullString   `qs:"e,omitempty"`
+				L []sql.NullString `qs:"l,comma"`
+				M map[string]sql.NullInt64
+			}{
+				E: sql.NullString{Valid: true},
+				L: []sql.NullString{{String: "a", Valid: true}, {}},
+				M: map[string]sql.NullInt64{"x": {Int64: 1, Valid: true}, "y": {}},
+			},
+			url.Values{"e": {""}, "l": {"a"}, "M[x]": {"1"}},
+		},
+	}
+
+	for _, tt := range tests {
+		testValue(t, tt.input, tt.want)
+	}
+
+	if _, err := Values(struct{ M money }{-1}); err == nil {
+		t.Errorf("Values with failing Valuer did not return error")
+	}
+}
+
+func TestEncoder_OmitNilValuer(t *testing.T) {
+	input := struct {
+		S sql.NullString
+		N sql.NullString
+	}{S: sql.NullString{String: "", Valid: true}}
+
+	for _, policy := range []OmitPolicy{OmitEmpty, OmitNil} {
+		testEncoderValue(t, NewEncoder(WithOmitPolicy(policy)), input, url.Values{"S": {""}})
+	}
+}
+
+func TestUnmarshal_Scanner(t *testing.T) {
+	type Filter struct {
+		S  sql.NullString
+		I  sql.NullInt64
+		F  sql.NullFloat64
+		B  sql.NullBool `qs:"b,int"`
+		T  sql.NullTime `qs:"t,unix"`
+		P  *sql.NullInt32
+		N  sql.NullString
+		C  csv
+		PN *sql.NullString
+	}
+	values := url.Values{
+		"S": {""},
+		"I": {"42"},
+		"F": {"1.5"},
+		"b": {"1"},
+		"t": {"1644597542"},
+		"P": {"7"},
+		"C": {"a,b"},
+	}
+	want := Filter{
+		S: sql.NullString{Valid: true},
+		I: sql.NullInt64{Int64: 42, Valid: true},
+		F: sql.NullFloat64{Float64: 1.5, Valid: true},
+		B: sql.NullBool{Bool: true, Valid: true},
+		T: sql.NullTime{Time: time.Unix(1644597542, 0).UTC(), Valid: true},
+		P: &sql.NullInt32{Int32: 7, Valid: true},
+		C: csv{"a", "b"},
+	}
+
+	var got Filter
+	if err := Unmarshal(values, &got); err != nil {
+		t.Fatalf("Unmarshal(%v) returned error: %v", values, err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Unmarshal(%v) mismatch:\n%s", values, diff)
+	}
+
+	if err := Unmarshal(url.Values{"I": {"x"}}, &got); err == nil {
+		t.Errorf("Unmarshal with invalid NullInt64 did not return error")
+	}
+}